@@ -17,12 +17,14 @@ const (
 type AppConfig struct {
 	Name string `mapstructure:"name" json:"name" yaml:"name"`
 	Env  string `mapstructure:"env" json:"env" yaml:"env"`
+
+	ConfigWatch bool `mapstructure:"config_watch" json:"config_watch" yaml:"config_watch"` // ConfigWatch 是否监听配置文件变更并热加载
 }
 
 // GetAppConf 获取应用基础配置
 func GetAppConf() (AppConfig, error) {
 	ac := AppConfig{}
-	err := Pick().UnmarshalKey(defaultScope, &ac)
+	err := Pick().UnmarshalKey("app", &ac)
 	if err != nil {
 		return ac, fmt.Errorf("%w: 解析应用基础配置错误: %w", kit.ErrDataUnmarshal, err)
 	}
@@ -34,14 +36,12 @@ func AppName() string {
 	return Pick().GetString("app.name")
 }
 
+// AppConfigWatch 获取配置的是否监听配置文件变更
+func AppConfigWatch() bool {
+	return Pick().GetBool("app.config_watch")
+}
+
 // AppEnv 获取配置的应用Env
 func AppEnv() string {
-	env := Pick().GetString("app.env")
-	if env == "" {
-		env = AppEnvDev
-	}
-	if env != AppEnvTest && env != AppEnvProd {
-		env = AppEnvDev
-	}
-	return env
+	return normalizeEnv(Pick().GetString("app.env"))
 }
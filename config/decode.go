@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// UnmarshalKeyStrict 严格解析配置实例中的key至rawVal 存在未知配置项 (例如拼写错误) 时返回错误
+func UnmarshalKeyStrict(cfg *viper.Viper, key string, rawVal any) error {
+	return cfg.UnmarshalKey(key, rawVal, viper.DecoderConfigOption(func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	}))
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量覆盖配置的前缀
+// 格式为 MYGO_{SCOPE}__{KEY} 其中SCOPE为大写的配置文件名 KEY以双下划线分隔层级 单下划线保持原样
+// 例如 MYGO_CONFIG__NDB__PASSWORD 覆盖 config.yaml[ndb.password]
+// 例如 MYGO_CONFIG__APP__ENV 覆盖 config.yaml[app.env] 并决定加载的环境覆盖文件
+const EnvPrefix = "MYGO_"
+
+// envSeparator 环境变量中的层级分隔符
+const envSeparator = "__"
+
+// isOverlay 判断文件名是否为环境覆盖文件 ({file}.{env})
+func isOverlay(file string) bool {
+	i := strings.LastIndex(file, ".")
+	if i <= 0 {
+		return false
+	}
+	switch file[i+1:] {
+	case AppEnvProd, AppEnvTest, AppEnvDev:
+		return true
+	}
+	return false
+}
+
+// resolveEnv 获取加载覆盖文件时使用的应用环境
+func resolveEnv(scope string, v *viper.Viper) string {
+	env := ""
+	if scope == defaultScope {
+		env = v.GetString("app.env")
+	} else if Exist(defaultScope) {
+		env = Pick().GetString("app.env")
+	}
+	return normalizeEnv(env)
+}
+
+// normalizeEnv 规范化应用环境 未知环境视为dev
+func normalizeEnv(env string) string {
+	if env != AppEnvTest && env != AppEnvProd {
+		return AppEnvDev
+	}
+	return env
+}
+
+// mergeOverlay 将环境覆盖文件 ({path}/{file}.{env}.{ext}) 合并至配置实例 文件不存在时忽略
+func mergeOverlay(src source, env string, v *viper.Viper) error {
+	name := filepath.Join(src.path, fmt.Sprintf("%s.%s.%s", src.file, env, src.ext))
+	content, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取环境覆盖配置文件[%s]错误: %w", name, err)
+	}
+	if err := v.MergeConfig(bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("合并环境覆盖配置文件[%s]错误: %w", name, err)
+	}
+	return nil
+}
+
+// mergeEnvOverrides 将环境变量覆盖合并至配置实例
+func mergeEnvOverrides(scope string, v *viper.Viper) error {
+	prefix := EnvPrefix + strings.ToUpper(scope) + envSeparator
+	overrides := map[string]any{}
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+		path := strings.Split(strings.ToLower(key[len(prefix):]), envSeparator)
+		node := overrides
+		for _, seg := range path[:len(path)-1] {
+			child, ok := node[seg].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[seg] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = value
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+	if err := v.MergeConfigMap(overrides); err != nil {
+		return fmt.Errorf("合并环境变量覆盖配置错误: %w", err)
+	}
+	return nil
+}
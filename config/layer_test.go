@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestIsOverlay(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"config", false},
+		{"config.prod", true},
+		{"config.test", true},
+		{"config.dev", true},
+		{"config.local", false},
+		{"app.config", false},
+		{".prod", false},
+		{"config.", false},
+	}
+	for _, tt := range tests {
+		if got := isOverlay(tt.file); got != tt.want {
+			t.Errorf("isOverlay(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestMergeEnvOverrides(t *testing.T) {
+	t.Setenv("MYGO_CONFIG__APP__ENV", "prod")
+	t.Setenv("MYGO_CONFIG__NDB__PASSWORD", "s3cret")
+	t.Setenv("MYGO_CONFIG__HTTP_SERVER__ADDR", ":9090")
+	t.Setenv("MYGO_CONFIG__NEW__NESTED__KEY", "v")
+	t.Setenv("MYGO_CONFIG__", "ignored")
+	t.Setenv("MYGO_CONFIG_APP__ENV", "ignored")
+	t.Setenv("MYGO_OTHER__APP__ENV", "ignored")
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+app:
+  env: dev
+  name: demo
+ndb:
+  host: 127.0.0.1
+  password: ""
+http_server:
+  addr: ":8888"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mergeEnvOverrides("config", v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"app.env":          "prod",
+		"app.name":         "demo",
+		"ndb.host":         "127.0.0.1",
+		"ndb.password":     "s3cret",
+		"http_server.addr": ":9090",
+		"new.nested.key":   "v",
+		"config_app.env":   "",
+		"other.app.env":    "",
+	}
+	for key, value := range want {
+		if got := v.GetString(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestMergeEnvOverridesScope(t *testing.T) {
+	t.Setenv("MYGO_RUNNER__JOB__TIMEOUT", "5s")
+
+	v := viper.New()
+	if err := mergeEnvOverrides("config", v); err != nil {
+		t.Fatal(err)
+	}
+	if v.IsSet("job.timeout") {
+		t.Error("override of other scope should not be merged")
+	}
+
+	v = viper.New()
+	if err := mergeEnvOverrides("runner", v); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.GetString("job.timeout"); got != "5s" {
+		t.Errorf("job.timeout = %q, want %q", got, "5s")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/samber/do"
 	"github.com/spf13/viper"
@@ -22,7 +23,21 @@ var extMap = map[string]string{
 	// ".json": "json",
 }
 
+// source 配置文件来源
+type source struct {
+	path string
+	file string
+	ext  string
+}
+
+var (
+	sourceMu sync.RWMutex
+	sources  = map[string]source{}
+)
+
 // Boot 预加载指定目录下全部配置文件实例
+// 配置按 基础文件({file}.yaml) -> 环境覆盖文件({file}.{app.env}.yaml) -> 环境变量(见 EnvPrefix) 的顺序合并
+// 合并后解析字符串值中的 ${VAR}、file:// 与 ENC(...) (见 resolveSecrets)
 func Boot(path string) error {
 	des, err := os.ReadDir(path)
 	if err != nil {
@@ -36,24 +51,48 @@ func Boot(path string) error {
 		ext := filepath.Ext(de.Name())
 		file := de.Name()[:len(de.Name())-len(ext)]
 		ext2, ok := extMap[ext]
-		if !ok {
+		if !ok || isOverlay(file) {
 			continue
 		}
+		src := source{path: path, file: file, ext: ext2}
+		sourceMu.Lock()
+		sources[file] = src
+		sourceMu.Unlock()
 		do.ProvideNamed(nil, iocPrefix+file, func(injector *do.Injector) (*viper.Viper, error) {
-			v := viper.New()
-			v.SetConfigName(file)
-			v.SetConfigType(ext2)
-			v.AddConfigPath(path)
-			err = v.ReadInConfig()
-			if err != nil {
-				return nil, err
-			}
-			return v, nil
+			return load(src)
 		})
 	}
 	return nil
 }
 
+// load 从配置文件来源加载配置实例
+func load(src source) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigName(src.file)
+	v.SetConfigType(src.ext)
+	v.AddConfigPath(src.path)
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	// 合并环境变量以确定应用环境
+	if err = mergeEnvOverrides(src.file, v); err != nil {
+		return nil, err
+	}
+	// 合并环境覆盖文件 环境变量优先级最高需再次合并
+	if err = mergeOverlay(src, resolveEnv(src.file, v), v); err != nil {
+		return nil, err
+	}
+	if err = mergeEnvOverrides(src.file, v); err != nil {
+		return nil, err
+	}
+	// 解析环境变量插值、文件引用与加密值
+	if err = resolveSecrets(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
 // Exist 判断指定scope实例是否挂载 (被Boot过) 且类型正确
 func Exist(scope string) bool {
 	_, err := do.InvokeNamed[*viper.Viper](nil, iocPrefix+scope)
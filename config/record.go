@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/do"
+	"github.com/spf13/viper"
+)
+
+// maskedValue 敏感配置项脱敏后的值
+const maskedValue = "******"
+
+// sensitiveKeys 配置项名包含以下任一片段时视为敏感配置项
+var sensitiveKeys = []string{"password", "secret", "token", "key", "dsn", "webhook"}
+
+// Record 已加载组件实例的生效配置
+type Record struct {
+	Component string `json:"component"`
+	Scope     string `json:"scope"`
+	Config    any    `json:"config"`
+}
+
+var (
+	recordMu sync.RWMutex
+	records  = map[string]Record{}
+	lazies   = map[string]lazyRecord{}
+)
+
+// lazyRecord 延迟获取的组件生效配置
+type lazyRecord struct {
+	component string
+	scope     string
+	fn        func() (any, error)
+}
+
+// Track 记录组件实例的生效配置 供配置查看与组件列表使用
+func Track(component, scope string, conf any) {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+	records[component+":"+scope] = Record{
+		Component: component,
+		Scope:     scope,
+		Config:    conf,
+	}
+}
+
+// TrackFunc 登记组件配置的获取函数 用于运行时才加载配置的组件 (例: http_server、cron)
+// 使服务运行前也可通过配置查看命令输出其生效配置
+// 组件未通过 Track 记录且config.yaml中存在scope配置时 Records 调用fn获取配置 获取失败时忽略
+func TrackFunc(component, scope string, fn func() (any, error)) {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+	lazies[component+":"+scope] = lazyRecord{component: component, scope: scope, fn: fn}
+}
+
+// Records 获取全部已记录的组件实例生效配置 按组件与scope排序
+func Records() []Record {
+	recordMu.RLock()
+	res := make([]Record, 0, len(records)+len(lazies))
+	for _, r := range records {
+		res = append(res, r)
+	}
+	pending := make([]lazyRecord, 0, len(lazies))
+	for k, l := range lazies {
+		if _, ok := records[k]; !ok {
+			pending = append(pending, l)
+		}
+	}
+	recordMu.RUnlock()
+	for _, l := range pending {
+		if v, err := do.InvokeNamed[*viper.Viper](nil, iocPrefix+defaultScope); err != nil || !v.IsSet(l.scope) {
+			continue
+		}
+		conf, err := l.fn()
+		if err != nil {
+			continue
+		}
+		res = append(res, Record{Component: l.component, Scope: l.scope, Config: conf})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Component != res[j].Component {
+			return res[i].Component < res[j].Component
+		}
+		return res[i].Scope < res[j].Scope
+	})
+	return res
+}
+
+// Mask 将配置结构转换为以mapstructure标签为键的map 并对敏感配置项脱敏
+func Mask(conf any) any {
+	return mask(reflect.ValueOf(conf), "")
+}
+
+func mask(v reflect.Value, name string) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String && v.Len() != 0 && isSensitive(name) {
+		return maskedValue
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		res := map[string]any{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			key := strings.Split(f.Tag.Get("mapstructure"), ",")[0]
+			if key == "-" {
+				continue
+			}
+			if key == "" {
+				key = f.Name
+			}
+			res[key] = mask(v.Field(i), key)
+		}
+		return res
+	case reflect.Map:
+		res := map[string]any{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			res[key] = mask(iter.Value(), key)
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		res := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res[i] = mask(v.Index(i), name)
+		}
+		return res
+	}
+	if s, ok := v.Interface().(interface{ String() string }); ok && v.Kind() != reflect.String {
+		return s.String()
+	}
+	return v.Interface()
+}
+
+// isSensitive 判断配置项名是否敏感
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, key := range sensitiveKeys {
+		if strings.Contains(name, key) {
+			return true
+		}
+	}
+	return false
+}
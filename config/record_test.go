@@ -0,0 +1,59 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMask(t *testing.T) {
+	type node struct {
+		Host     string `mapstructure:"host"`
+		Password string `mapstructure:"password"`
+	}
+	type conf struct {
+		Addr      string            `mapstructure:"addr"`
+		Timeout   time.Duration     `mapstructure:"timeout"`
+		AccessKey string            `mapstructure:"access_key"`
+		Webhook   string            `mapstructure:"webhook"`
+		Empty     string            `mapstructure:"secret"`
+		Nodes     []node            `mapstructure:"nodes"`
+		Tokens    []string          `mapstructure:"tokens"`
+		Headers   map[string]string `mapstructure:"headers"`
+		Ptr       *node             `mapstructure:"ptr"`
+		Nil       *node             `mapstructure:"nil"`
+		Ignored   string            `mapstructure:"-"`
+		Untagged  int
+		internal  string
+	}
+	c := conf{
+		Addr:      ":8888",
+		Timeout:   3 * time.Second,
+		AccessKey: "ak",
+		Webhook:   "https://example.com/hook",
+		Nodes:     []node{{Host: "h1", Password: "p1"}},
+		Tokens:    []string{"t1", "t2"},
+		Headers:   map[string]string{"X-Api-Token": "t", "Accept": "json"},
+		Ptr:       &node{Host: "h2", Password: "p2"},
+		Ignored:   "x",
+		Untagged:  1,
+		internal:  "x",
+	}
+
+	want := map[string]any{
+		"addr":       ":8888",
+		"timeout":    "3s",
+		"access_key": maskedValue,
+		"webhook":    maskedValue,
+		"secret":     "",
+		"nodes":      []any{map[string]any{"host": "h1", "password": maskedValue}},
+		"tokens":     []any{maskedValue, maskedValue},
+		"headers":    map[string]any{"X-Api-Token": maskedValue, "Accept": "json"},
+		"ptr":        map[string]any{"host": "h2", "password": maskedValue},
+		"nil":        nil,
+		"Untagged":   1,
+	}
+	if got := Mask(&c); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Mask = %#v, want %#v", got, want)
+	}
+}
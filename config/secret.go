@@ -0,0 +1,196 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// SecretKeyEnv 解密 ENC(...) 配置值所用密钥的环境变量名
+const SecretKeyEnv = "MYGO_SECRET_KEY"
+
+const (
+	encPrefix  = "ENC("
+	encSuffix  = ")"
+	filePrefix = "file://"
+)
+
+// envPattern 匹配 ${VAR} 与 ${VAR:-default}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// resolveSecrets 解析配置实例中的全部字符串值
+// 支持:
+// ${VAR} / ${VAR:-default}: 以环境变量插值
+// file:///path/to/secret: 读取文件内容 (例如Kubernetes Secret挂载)
+// ENC(...): 以环境变量 SecretKeyEnv 中的密钥解密
+func resolveSecrets(v *viper.Viper) error {
+	changed, err := resolveMap(v.AllSettings(), "")
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return v.MergeConfigMap(changed)
+}
+
+// resolveMap 解析map中的值 仅返回发生变化的部分
+func resolveMap(m map[string]any, prefix string) (map[string]any, error) {
+	changed := map[string]any{}
+	for k, val := range m {
+		nv, ok, err := resolveAny(val, prefix+k)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed[k] = nv
+		}
+	}
+	return changed, nil
+}
+
+// resolveAny 解析任意值 返回解析后的值以及是否发生变化
+func resolveAny(val any, key string) (any, bool, error) {
+	switch t := val.(type) {
+	case string:
+		s, err := resolveValue(t)
+		if err != nil {
+			return nil, false, fmt.Errorf("解析配置[%s]错误: %w", key, err)
+		}
+		return s, s != t, nil
+	case map[string]any:
+		sub, err := resolveMap(t, key+".")
+		if err != nil {
+			return nil, false, err
+		}
+		return sub, len(sub) != 0, nil
+	case []any:
+		res := make([]any, len(t))
+		changed := false
+		for i, item := range t {
+			nv, ok, err := resolveAny(item, fmt.Sprintf("%s[%d]", key, i))
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				changed = true
+				res[i] = nv
+				// 列表整体替换 map元素需保留未变化的键
+				if om, isMap := item.(map[string]any); isMap {
+					res[i] = mergeChanged(om, nv.(map[string]any))
+				}
+			} else {
+				res[i] = item
+			}
+		}
+		return res, changed, nil
+	}
+	return val, false, nil
+}
+
+// mergeChanged 将resolveMap返回的变化部分合并到原map的副本中
+func mergeChanged(orig, changed map[string]any) map[string]any {
+	res := make(map[string]any, len(orig))
+	for k, v := range orig {
+		res[k] = v
+	}
+	for k, v := range changed {
+		om, ok1 := orig[k].(map[string]any)
+		cm, ok2 := v.(map[string]any)
+		if ok1 && ok2 {
+			res[k] = mergeChanged(om, cm)
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// resolveValue 解析单个字符串值
+func resolveValue(s string) (string, error) {
+	if strings.HasPrefix(s, encPrefix) && strings.HasSuffix(s, encSuffix) {
+		key := os.Getenv(SecretKeyEnv)
+		if key == "" {
+			return "", fmt.Errorf("%w: 存在加密配置值但未设置环境变量[%s]", kit.ErrNotFound, SecretKeyEnv)
+		}
+		return Decrypt(s, key)
+	}
+	if strings.HasPrefix(s, filePrefix) {
+		content, err := os.ReadFile(s[len(filePrefix):])
+		if err != nil {
+			return "", fmt.Errorf("读取配置引用文件错误: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	var err error
+	res := envPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := envPattern.FindStringSubmatch(m)
+		if val, ok := os.LookupEnv(sub[1]); ok {
+			return val
+		}
+		if sub[2] != "" {
+			return sub[3]
+		}
+		err = fmt.Errorf("%w: 配置引用的环境变量[%s]未设置", kit.ErrNotFound, sub[1])
+		return m
+	})
+	return res, err
+}
+
+// Encrypt 加密配置值 返回可直接写入配置文件的 ENC(...) 格式
+func Encrypt(plain, key string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return encPrefix + base64.StdEncoding.EncodeToString(sealed) + encSuffix, nil
+}
+
+// Decrypt 解密 ENC(...) 格式的配置值
+func Decrypt(value, key string) (string, error) {
+	if !strings.HasPrefix(value, encPrefix) || !strings.HasSuffix(value, encSuffix) {
+		return "", fmt.Errorf("%w: 加密配置值需为ENC(...)格式", kit.ErrDataFormat)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[len(encPrefix) : len(value)-len(encSuffix)])
+	if err != nil {
+		return "", fmt.Errorf("%w: 加密配置值非法: %w", kit.ErrDataFormat, err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: 加密配置值长度非法", kit.ErrDataFormat)
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("解密配置值失败, 请检查密钥是否正确")
+	}
+	return string(plain), nil
+}
+
+// newGCM 以密钥的SHA-256摘要创建AES-256-GCM实例
+func newGCM(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
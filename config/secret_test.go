@@ -0,0 +1,43 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveSecretsNestedList(t *testing.T) {
+	t.Setenv("PW", "s3cret")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+targets:
+  - name: a
+    password: ${PW}
+    extra:
+      host: h1
+      token: ${PW}
+  - name: b
+    password: plain
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resolveSecrets(v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{
+		map[string]any{
+			"name":     "a",
+			"password": "s3cret",
+			"extra":    map[string]any{"host": "h1", "token": "s3cret"},
+		},
+		map[string]any{"name": "b", "password": "plain"},
+	}
+	if got := v.Get("targets"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/samber/do"
+	"github.com/spf13/viper"
+)
+
+// reloadDelay 文件变更后延迟重载的时间 用于合并编辑器的多次写入
+const reloadDelay = 200 * time.Millisecond
+
+type subscription struct {
+	key string
+	fn  func(v *viper.Viper)
+}
+
+var (
+	watchMu       sync.RWMutex
+	subscriptions = map[string][]subscription{}
+	validators    = map[string][]func(v *viper.Viper) error{}
+)
+
+// OnChange 订阅指定scope配置中key的变更 key为空时订阅整个scope
+// 仅在开启监听 (Watch) 后生效 回调参数为变更后的配置实例
+func OnChange(scope, key string, fn func(v *viper.Viper)) {
+	if scope == "" {
+		scope = defaultScope
+	}
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	subscriptions[scope] = append(subscriptions[scope], subscription{key: key, fn: fn})
+}
+
+// OnValidate 注册指定scope配置的校验器 校验失败时拒绝本次变更并保留原配置
+func OnValidate(scope string, fn func(v *viper.Viper) error) {
+	if scope == "" {
+		scope = defaultScope
+	}
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	validators[scope] = append(validators[scope], fn)
+}
+
+// Watch 监听已加载的配置文件 文件变更时重新加载并通知订阅者
+// 返回的stop函数用于停止监听
+func Watch() (stop func() error, err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建配置文件监听器错误: %w", err)
+	}
+
+	sourceMu.RLock()
+	dirs := map[string]struct{}{}
+	for _, src := range sources {
+		dirs[filepath.Clean(src.path)] = struct{}{}
+	}
+	sourceMu.RUnlock()
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("监听配置目录[%s]错误: %w", dir, err)
+		}
+	}
+
+	go func() {
+		timers := map[string]*time.Timer{}
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+					continue
+				}
+				for _, scope := range affectedScopes(event.Name) {
+					if t, ok := timers[scope]; ok {
+						t.Reset(reloadDelay)
+						continue
+					}
+					timers[scope] = time.AfterFunc(reloadDelay, func() {
+						reload(scope)
+					})
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintln(os.Stdout, "监听配置文件错误:", err)
+			}
+		}
+	}()
+
+	return watcher.Close, nil
+}
+
+// affectedScopes 获取文件变更影响的scope列表
+func affectedScopes(name string) []string {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+
+	base := filepath.Base(name)
+	scopes := make([]string, 0, 1)
+	for scope, src := range sources {
+		// Kubernetes ConfigMap以软链接切换目录(..data) 此时全部scope均需检查
+		if strings.HasPrefix(base, "..") {
+			scopes = append(scopes, scope)
+			continue
+		}
+		if strings.HasPrefix(base, src.file+".") {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// reload 重新加载指定scope配置 加载或校验失败时保留原配置
+func reload(scope string) {
+	sourceMu.RLock()
+	src, ok := sources[scope]
+	sourceMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	old, err := do.InvokeNamed[*viper.Viper](nil, iocPrefix+scope)
+	if err != nil {
+		return
+	}
+	v, err := load(src)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "配置[%s]变更被拒绝, 保留原配置, 原因: %s\n", scope, err)
+		return
+	}
+	if reflect.DeepEqual(old.AllSettings(), v.AllSettings()) {
+		return
+	}
+
+	watchMu.RLock()
+	vs := validators[scope]
+	subs := subscriptions[scope]
+	watchMu.RUnlock()
+	for _, validate := range vs {
+		if err := validate(v); err != nil {
+			fmt.Fprintf(os.Stdout, "配置[%s]变更被拒绝, 保留原配置, 原因: %s\n", scope, err)
+			return
+		}
+	}
+
+	// 替换配置实例并通知订阅者
+	do.OverrideNamedValue(nil, iocPrefix+scope, v)
+	fmt.Fprintf(os.Stdout, "配置[%s]已重新加载\n", scope)
+	for _, sub := range subs {
+		if sub.key != "" && reflect.DeepEqual(old.Get(sub.key), v.Get(sub.key)) {
+			continue
+		}
+		notify(scope, sub, v)
+	}
+}
+
+// notify 通知订阅者 订阅者panic不影响其他订阅者
+func notify(scope string, sub subscription, v *viper.Viper) {
+	defer func() {
+		if pnc := recover(); pnc != nil {
+			fmt.Fprintf(os.Stdout, "配置[%s]变更订阅[%s]发生panic: %v\n", scope, sub.key, pnc)
+		}
+	}()
+	sub.fn(v)
+}
@@ -1,5 +1,7 @@
 package cube
 
+import "errors"
+
 var DefaultConfig = Config{
 	BaseURL:    "",
 	APIKey:     "",
@@ -16,3 +18,11 @@ type Config struct {
 	// 基础依赖组件实例配置
 	Resty string `mapstructure:"resty"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.BaseURL == "" {
+		return errors.New("base_url不能为空")
+	}
+	return nil
+}
@@ -58,6 +58,7 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, client)
+	config.Track("cube", scope, conf)
 
 	return nil
 }
@@ -76,11 +77,16 @@ func getConf(scope string) (conf Config, err error) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
 
-	// 解析 config.yaml[{scope}]
-	if err = cfg.UnmarshalKey(scope, &conf); err != nil {
+	// 严格解析 config.yaml[{scope}]
+	if err = config.UnmarshalKeyStrict(cfg, scope, &conf); err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
 
+	// 校验配置
+	if err = conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
+
 	return conf, nil
 }
 
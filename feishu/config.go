@@ -1,6 +1,7 @@
 package feishu
 
 import (
+	"errors"
 	"time"
 )
 
@@ -52,3 +53,11 @@ type Config struct {
 	DialContextTimeout     time.Duration `mapstructure:"dial_context_timeout"`
 	DialContextKeepAlive   time.Duration `mapstructure:"dial_context_keep_alive"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Enable && c.NoticeWebhook == "" {
+		return errors.New("启用时notice_webhook不能为空")
+	}
+	return nil
+}
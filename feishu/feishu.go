@@ -1,12 +1,15 @@
 package feishu
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -23,6 +26,7 @@ type result struct {
 type Feishu struct {
 	conf   Config
 	client *resty.Client
+	enable atomic.Bool
 }
 
 // New 以指定配置创建实例
@@ -55,10 +59,17 @@ func New(conf Config) *Feishu {
 	// 初始化resty Client
 	client := resty.NewWithClient(hc)
 
-	return &Feishu{
+	f := &Feishu{
 		conf:   conf,
 		client: client,
 	}
+	f.enable.Store(conf.Enable)
+	return f
+}
+
+// SetEnable 设置是否启用发送 用于配置热更新
+func (f *Feishu) SetEnable(enable bool) {
+	f.enable.Store(enable)
 }
 
 // Send 发送飞书Bot消息 https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot
@@ -67,7 +78,7 @@ func New(conf Config) *Feishu {
 // message: 消息内容
 func (f *Feishu) Send(title, message string) error {
 	// 未启用
-	if !f.conf.Enable {
+	if !f.enable.Load() {
 		return nil
 	}
 
@@ -127,6 +138,29 @@ func (f *Feishu) Send(title, message string) error {
 	return nil
 }
 
+// Check 检查飞书Bot Webhook地址是否可达 未启用时不做检查
+func (f *Feishu) Check(ctx context.Context) error {
+	if !f.enable.Load() {
+		return nil
+	}
+	u, err := url.Parse(f.conf.NoticeWebhook)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: 飞书Bot Webhook地址非法[%s]", kit.ErrDataFormat, f.conf.NoticeWebhook)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("连接飞书Bot Webhook地址错误: %w", err)
+	}
+	return conn.Close()
+}
+
 // genSign 计算签名 https://open.feishu.cn/document/client-docs/bot-v3/add-custom-bot?lang=zh-CN#3c6592d6
 func (f *Feishu) genSign(secret string, timestamp int64) (string, error) {
 	//timestamp + key 做sha256 再进行base64 encode
@@ -1,12 +1,16 @@
 package feishu
 
 import (
+	"context"
 	"fmt"
+	"log"
 
 	"github.com/jinzhu/copier"
 	"github.com/samber/do"
+	"github.com/spf13/viper"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/health"
 	"github.com/zjutjh/mygo/kit"
 )
 
@@ -49,6 +53,21 @@ func Pick(scopes ...string) *Feishu {
 	return do.MustInvokeNamed[*Feishu](nil, iocPrefix+scope)
 }
 
+// Alert 使用默认实例异步发送报警 未加载默认实例时忽略 发送过程中的panic不影响调用方
+func Alert(title, message string) {
+	if !Exist() {
+		return
+	}
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Println("请求飞书Bot发送报警发生了Panic:", err)
+			}
+		}()
+		_ = Pick().Send(title, message)
+	}()
+}
+
 // provide 提供指定scope实例
 func provide(scope string) error {
 	// 获取配置
@@ -62,27 +81,51 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("feishu", scope, conf)
+
+	// 订阅配置变更 热更新启用开关 非法变更被拒绝并保留原配置
+	config.OnValidate("", func(v *viper.Viper) error {
+		_, err := decodeConf(v, scope)
+		return err
+	})
+	config.OnChange("", scope+".enable", func(v *viper.Viper) {
+		instance.SetEnable(v.GetBool(scope + ".enable"))
+	})
+
+	// 注册健康检查 报警通道不可达不影响业务请求 不参与就绪判定
+	health.RegisterOptional(fmt.Sprintf("feishu[%s]", scope), func(ctx context.Context) error {
+		return instance.Check(ctx)
+	})
 
 	return nil
 }
 
 // getConf 获取配置
 func getConf(scope string) (conf Config, err error) {
+	return decodeConf(config.Pick(), scope)
+}
+
+// decodeConf 从配置实例中解析并校验配置
+func decodeConf(cfg *viper.Viper, scope string) (conf Config, err error) {
 	// 初始化默认配置
 	conf, err = defaultConfig()
 	if err != nil {
 		return conf, err
 	}
 	// 判断 scope 配置是否存在
-	cfg := config.Pick()
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
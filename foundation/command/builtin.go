@@ -0,0 +1,227 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/httpserver"
+	"github.com/zjutjh/mygo/foundation/kernel"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// 构建信息 通过ldflags注入
+// 例: go build -ldflags "-X github.com/zjutjh/mygo/foundation/command.Version=v1.0.0 -X github.com/zjutjh/mygo/foundation/command.Commit=$(git rev-parse HEAD)"
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+// registerBuiltin 注册内置命令 需在业务命令注册后调用 与业务命令同名(含别名)时跳过 业务命令优先
+func registerBuiltin(root *cobra.Command) {
+	for _, cmd := range []*cobra.Command{versionCommand(), routesCommand(), providersCommand(), configCommand()} {
+		if commandTaken(root, cmd.Name()) {
+			continue
+		}
+		root.AddCommand(cmd)
+	}
+}
+
+// commandTaken 判断命令名是否已被子命令的名称或别名占用
+func commandTaken(root *cobra.Command, name string) bool {
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionCommand 查看构建信息命令
+func versionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "查看构建信息",
+		Long:  "查看构建信息 包含ldflags注入的版本信息与debug.ReadBuildInfo读取的模块及VCS信息",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := map[string]string{
+				"version":    Version,
+				"commit":     Commit,
+				"build_time": BuildTime,
+				"go_version": runtime.Version(),
+				"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+			}
+			if bi, ok := debug.ReadBuildInfo(); ok {
+				info["module"] = bi.Main.Path
+				info["module_version"] = bi.Main.Version
+				for _, dep := range bi.Deps {
+					if dep.Path == "github.com/zjutjh/mygo" {
+						info["mygo_version"] = dep.Version
+					}
+				}
+				for _, s := range bi.Settings {
+					switch s.Key {
+					case "vcs.revision":
+						if info["commit"] == "" {
+							info["commit"] = s.Value
+						}
+					case "vcs.time":
+						if info["build_time"] == "" {
+							info["build_time"] = s.Value
+						}
+					case "vcs.modified":
+						info["vcs_modified"] = s.Value
+					}
+				}
+			}
+			if info["version"] == "" {
+				info["version"] = info["module_version"]
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, key := range []string{"version", "commit", "build_time", "vcs_modified", "module", "module_version", "mygo_version", "go_version", "platform"} {
+				if info[key] != "" {
+					fmt.Fprintf(w, "%s:\t%s\n", key, info[key])
+				}
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// routesCommand 查看HTTP路由表命令
+func routesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "查看HTTP路由表",
+		Long:  "引导应用并输出HTTP Server的路由表及各路由的中间件链",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := kernel.BootstrapE(cfgPath, boot); err != nil {
+				return err
+			}
+			defer kernel.Shutdown()
+
+			// 关闭gin调试输出 避免注册路由时的日志混入路由表
+			gin.SetMode(gin.ReleaseMode)
+			engine, err := httpserver.Engine()
+			if err != nil {
+				return err
+			}
+			routes, err := httpserver.RouteTable(engine)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARES")
+			for _, r := range routes {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Handler, strings.Join(r.Middlewares, " -> "))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// providersCommand 查看已加载组件实例命令
+func providersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "providers",
+		Short: "查看已加载组件实例",
+		Long:  "引导应用并输出全部已加载的组件实例(ndb、nedis、nlog等)及其scope",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := kernel.BootstrapE(cfgPath, boot); err != nil {
+				return err
+			}
+			defer kernel.Shutdown()
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "COMPONENT\tSCOPE")
+			for _, r := range config.Records() {
+				fmt.Fprintf(w, "%s\t%s\n", r.Component, r.Scope)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// configCommand 配置相关命令 直接运行时引导应用并输出全部已加载组件的生效配置 (敏感配置项已脱敏)
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "查看生效配置",
+		Long:  "引导应用并输出全部已加载组件实例的生效配置 敏感配置项已脱敏",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := kernel.BootstrapE(cfgPath, boot); err != nil {
+				return err
+			}
+			defer kernel.Shutdown()
+			return printConfig()
+		},
+	}
+
+	var key string
+	encrypt := &cobra.Command{
+		Use:   "encrypt <value>",
+		Short: "加密配置值",
+		Long:  fmt.Sprintf("加密配置值 输出可直接写入配置文件的ENC(...) 密钥默认读取环境变量[%s]", config.SecretKeyEnv),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if key == "" {
+				key = os.Getenv(config.SecretKeyEnv)
+			}
+			if key == "" {
+				return errors.New("未指定加密密钥, 请通过--key或环境变量" + config.SecretKeyEnv + "指定")
+			}
+			value, err := config.Encrypt(args[0], key)
+			if err != nil {
+				return fmt.Errorf("加密配置值错误: %w", err)
+			}
+			fmt.Fprintln(os.Stdout, value)
+			return nil
+		},
+	}
+	encrypt.Flags().StringVar(&key, "key", "", "encrypt key(default is $"+config.SecretKeyEnv+")")
+	cmd.AddCommand(encrypt)
+
+	return cmd
+}
+
+// printConfig 以JSON格式输出应用基础配置与全部已加载组件实例的生效配置
+func printConfig() error {
+	app, err := config.GetAppConf()
+	if err != nil {
+		return err
+	}
+	components := make([]config.Record, 0)
+	for _, r := range config.Records() {
+		r.Config = config.Mask(r.Config)
+		components = append(components, r)
+	}
+	out, err := json.MarshalIndent(map[string]any{
+		"app":        app,
+		"components": components,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: 序列化配置错误: %w", kit.ErrDataMarshal, err)
+	}
+	fmt.Fprintln(os.Stdout, string(out))
+	return nil
+}
@@ -1,9 +1,12 @@
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 
 	"github.com/zjutjh/mygo/config"
 	"github.com/zjutjh/mygo/foundation/kernel"
+	"github.com/zjutjh/mygo/kit"
 	"github.com/zjutjh/mygo/nlog"
 )
 
@@ -22,13 +26,10 @@ var runningCommand *cobra.Command
 
 var once sync.Once
 var root = &cobra.Command{
-	Use: "app",
-	Run: func(cmd *cobra.Command, args []string) {
-		if runningCommand == nil || (runningCommand.Use != "app" && runningCommand.Use != "server") {
-			runningCommand = cmd
-		}
-		kernel.Bootstrap(cfgPath, boot)
-		Run(defaultRun, cmd, args)
+	Use:           "app",
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bootAndRun(defaultRun, cmd, args)
 	},
 }
 
@@ -36,73 +37,119 @@ func init() {
 	root.PersistentFlags().StringVar(&cfgPath, "config", "conf/", "config path(default is conf/)")
 }
 
-// Execute 应用程序执行主入口
+// Execute 应用程序执行主入口 命令执行失败时以非0状态码退出
 func Execute(b func() kernel.BootList, rc func(*cobra.Command), dr func(cmd *cobra.Command, args []string) error) {
 	once.Do(func() {
 		// 设置引导器与默认运行器
 		boot = b
 		defaultRun = dr
 
-		// 注册业务命令
+		// 注册业务命令与内置命令 同名时业务命令优先
 		rc(root)
+		registerBuiltin(root)
 
 		// 执行
 		if err := root.Execute(); err != nil {
-			fmt.Fprintln(os.Stdout, "执行命令错误", err)
-			os.Exit(1)
+			code := ExitCode(err)
+			if code == 0 {
+				return
+			}
+			fmt.Fprintln(os.Stdout, "执行命令错误:", err)
+			os.Exit(code)
 		}
 	})
 }
 
-// Add 注册一个命令
+// Add 注册一个命令 需要声明flag或子命令时使用 Register
 func Add(key string, runner func(cmd *cobra.Command, args []string) error) {
-	cmd := &cobra.Command{
-		Use:   key,
-		Short: fmt.Sprintf("运行命令[%s]", key),
-		Long:  fmt.Sprintf("运行命令[%s]", key),
-		Run: func(cmd *cobra.Command, args []string) {
-			if runningCommand == nil || (runningCommand.Use != "app" && runningCommand.Use != "server") {
-				runningCommand = cmd
-			}
-			kernel.Bootstrap(cfgPath, boot)
-			Run(runner, cmd, args)
+	Register(Spec{
+		Use: key,
+		Run: func(ctx *Context) error {
+			return runner(ctx.Cmd, ctx.Args)
 		},
+	})
+}
+
+// bootAndRun 引导应用并运行命令 结束后执行关闭钩子
+func bootAndRun(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Command, args []string) error {
+	// 参数解析通过后 运行错误不再打印用法
+	cmd.SilenceUsage = true
+
+	if runningCommand == nil || (runningCommand.Use != "app" && runningCommand.Use != "server") {
+		runningCommand = cmd
 	}
-	root.AddCommand(cmd)
+	if err := kernel.BootstrapE(cfgPath, boot); err != nil {
+		return err
+	}
+	defer kernel.Shutdown()
+	return RunE(runner, cmd, args)
 }
 
-// Run 运行命令
+// Run 运行命令 命令内部初始化错误时退出进程 运行器错误仅记录
 func Run(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Command, args []string) {
+	err := RunE(runner, cmd, args)
+	if err != nil && !errors.Is(err, kit.ErrCommandRun) {
+		fmt.Fprintln(os.Stdout, err)
+		os.Exit(1)
+	}
+}
+
+// RunE 运行命令 返回命令初始化错误或运行器错误 (运行器错误包装为 kit.ErrCommandRun)
+// 运行器返回 ExitError 时 Execute 以其指定的状态码退出进程
+func RunE(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Command, args []string) error {
+	return run(runner, cmd, args, false)
+}
+
+// cpuProfileMu CPU profile进程内全局唯一 同一时刻仅一次命令运行可采集
+var cpuProfileMu sync.Mutex
+
+// run 运行命令 inProcess为进程内调用 (Invoke)
+// 进程内调用可能并发运行: pprof输出文件名附加运行时刻以免互相覆盖 CPU profile被其他运行占用时跳过采集
+func run(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Command, args []string, inProcess bool) error {
+	name := commandName(cmd)
+
 	// 初始化配置和日志实例
 	conf := DefaultConfig
-	err := config.Pick().UnmarshalKey("command", &conf)
+	err := config.UnmarshalKeyStrict(config.Pick(), "command", &conf)
 	if err != nil {
-		fmt.Fprintln(os.Stdout, "初始化命令配置错误", err)
-		os.Exit(1)
+		return fmt.Errorf("%w: 初始化命令配置错误: %w", kit.ErrDataUnmarshal, err)
+	}
+	// 命令上下文缺少请求上下文元数据时生成 在HTTP请求或定时任务中调用时沿用调用方的请求ID与链路ID
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	logger := nlog.Pick(conf.Logger)
+	ctx = kit.EnsureMeta(ctx)
+	cmd.SetContext(ctx)
+	logger := nlog.Pick(conf.Logger).WithContext(ctx)
 
 	// 启动pprof
 	if conf.PprofSwitch {
+		base := conf.PprofOutput + strings.ReplaceAll(name, " ", "_") + ".run"
+		if inProcess {
+			base += "." + time.Now().Format("20060102150405.000000")
+		}
 		for _, t := range conf.PprofType {
 			switch t {
 			case "cpu":
-				w, err := os.OpenFile(conf.PprofOutput+cmd.Use+".run.cpu", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+				if !cpuProfileMu.TryLock() {
+					logger.Warnf("命令[%s]CPU pprof被其他运行中的命令占用, 跳过本次采集", name)
+					continue
+				}
+				defer cpuProfileMu.Unlock()
+				w, err := os.OpenFile(base+".cpu", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 				if err != nil {
-					fmt.Fprintln(os.Stdout, "处理命令CPU pprof错误", err)
-					os.Exit(1)
+					return fmt.Errorf("处理命令CPU pprof错误: %w", err)
 				}
 				err = pprof.StartCPUProfile(w)
 				if err != nil {
-					fmt.Fprintln(os.Stdout, "启动命令CPU pprof错误", err)
-					os.Exit(1)
+					return fmt.Errorf("启动命令CPU pprof错误: %w", err)
 				}
 				defer pprof.StopCPUProfile()
 			default:
-				w, err := os.OpenFile(conf.PprofOutput+cmd.Use+".run."+t, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+				w, err := os.OpenFile(base+"."+t, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 				if err != nil {
-					fmt.Fprintln(os.Stdout, fmt.Sprintf("处理命令%s pprof错误", t), err)
-					os.Exit(1)
+					return fmt.Errorf("处理命令%s pprof错误: %w", t, err)
 				}
 				defer w.Close()
 				defer pprof.Lookup(t).WriteTo(w, 0)
@@ -117,18 +164,18 @@ func Run(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Comman
 	defer func() {
 		if pnc := recover(); pnc != nil {
 			if conf.Output {
-				fmt.Fprintf(os.Stdout, "命令[%s]发生panic, 耗时[%s]\n", cmd.Use, time.Since(start).String())
+				fmt.Fprintf(os.Stdout, "命令[%s]发生panic, 耗时[%s]\n", name, time.Since(start).String())
 			}
-			logger.Errorf("命令[%s]发生panic, 耗时[%s]", cmd.Use, time.Since(start).String())
+			logger.Errorf("命令[%s]发生panic, 耗时[%s]", name, time.Since(start).String())
 			panic(pnc)
 		}
 	}()
 
 	// 声明开始执行信息
 	if conf.Output {
-		fmt.Fprintf(os.Stdout, "命令[%s]开始执行\n", cmd.Use)
+		fmt.Fprintf(os.Stdout, "命令[%s]开始执行\n", name)
 	}
-	logger.WithField("args", args).Infof("命令[%s]开始执行", cmd.Use)
+	logger.WithField("args", args).Infof("命令[%s]开始执行", name)
 
 	// 执行命名逻辑
 	err = runner(cmd, args)
@@ -136,18 +183,28 @@ func Run(runner func(cmd *cobra.Command, args []string) error, cmd *cobra.Comman
 	// 声明执行结果
 	if err == nil {
 		if conf.Output {
-			fmt.Fprintf(os.Stdout, "命令[%s]执行成功, 耗时[%s]\n", cmd.Use, time.Since(start).String())
+			fmt.Fprintf(os.Stdout, "命令[%s]执行成功, 耗时[%s]\n", name, time.Since(start).String())
 		}
-		logger.Infof("命令[%s]执行成功, 耗时[%s]", cmd.Use, time.Since(start).String())
-	} else {
-		if conf.Output {
-			fmt.Fprintf(os.Stdout, "命令[%s]发生错误, 耗时[%s], 错误: %s\n", cmd.Use, time.Since(start).String(), err.Error())
-		}
-		logger.WithError(err).Errorf("命令[%s]发生错误, 耗时[%s]", cmd.Use, time.Since(start).String())
+		logger.Infof("命令[%s]执行成功, 耗时[%s]", name, time.Since(start).String())
+		return nil
+	}
+	if conf.Output {
+		fmt.Fprintf(os.Stdout, "命令[%s]发生错误, 耗时[%s], 错误: %s\n", name, time.Since(start).String(), err.Error())
 	}
+	logger.WithError(err).Errorf("命令[%s]发生错误, 耗时[%s]", name, time.Since(start).String())
+	return fmt.Errorf("%w: 命令[%s]: %w", kit.ErrCommandRun, name, err)
 }
 
 // GetRunCommand 对外暴露正在运行的 command 信息
 func GetRunCommand() *cobra.Command {
 	return runningCommand
 }
+
+// commandName 获取命令路径 (不含根命令) 例: "user import"
+func commandName(cmd *cobra.Command) string {
+	path := cmd.CommandPath()
+	if r := cmd.Root(); r != cmd {
+		path = strings.TrimPrefix(path, r.Name()+" ")
+	}
+	return path
+}
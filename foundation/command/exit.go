@@ -0,0 +1,40 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExitError 指定进程退出状态码的命令错误
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exit 创建以指定状态码退出进程的命令错误
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode 获取错误对应的进程退出状态码 无错误为0 未指定状态码的错误为1
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	return 1
+}
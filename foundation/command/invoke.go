@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// Invoke 在当前进程中运行已注册命令 复用 RunE 的耗时记录、panic日志与pprof处理
+// pprof输出文件名附加运行时刻 CPU profile被其他运行占用时跳过采集
+// 调用前应用需已完成引导 每次调用创建独立的cobra命令 可并发调用
+// 参数:
+// path: 命令路径 多级命令以空格分隔 例: "user import"
+// args: 命令行参数 可包含flag 例: []string{"a.csv", "--batch=100"}
+func Invoke(ctx context.Context, path string, args []string) error {
+	segments := strings.Fields(path)
+	path = strings.Join(segments, " ")
+	spec, ok := Lookup(path)
+	if !ok || spec.Run == nil {
+		return fmt.Errorf("%w: 命令[%s]", kit.ErrNotFound, path)
+	}
+
+	// 重建命令层级 使命令路径与继承的persistent flag和命令行运行时一致
+	parent := &cobra.Command{Use: root.Use}
+	for i := range segments[:len(segments)-1] {
+		ancestor := &cobra.Command{Use: segments[i]}
+		if s, ok := Lookup(strings.Join(segments[:i+1], " ")); ok {
+			for _, f := range s.Flags {
+				if f.Persistent {
+					addFlag(ancestor, f)
+				}
+			}
+		}
+		parent.AddCommand(ancestor)
+		parent = ancestor
+	}
+	cmd := newCommand(spec)
+	parent.AddCommand(cmd)
+
+	// 解析flag与位置参数
+	if err := cmd.ParseFlags(args); err != nil {
+		return fmt.Errorf("%w: 命令[%s]参数错误: %w", kit.ErrRequestInvalidParamter, path, err)
+	}
+	positional := cmd.Flags().Args()
+	if err := cmd.ValidateArgs(positional); err != nil {
+		return fmt.Errorf("%w: 命令[%s]参数错误: %w", kit.ErrRequestInvalidParamter, path, err)
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return fmt.Errorf("%w: 命令[%s]参数错误: %w", kit.ErrRequestInvalidParamter, path, err)
+	}
+
+	cmd.SetContext(ctx)
+	return run(spec.runner(), cmd, positional, true)
+}
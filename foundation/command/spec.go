@@ -0,0 +1,224 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Spec 命令声明
+type Spec struct {
+	Use     string               // 命令名及参数说明 例: "import <file>"
+	Aliases []string             // 命令别名
+	Short   string               // 简短说明 默认为"运行命令[{path}]"
+	Long    string               // 详细说明 默认同Short
+	Example string               // 使用示例
+	Group   string               // 在父命令帮助信息中的分组标题
+	Args    cobra.PositionalArgs // 位置参数校验 例: cobra.ExactArgs(1)
+	Flags   []Flag               // 命令flag声明
+	Run     func(ctx *Context) error
+	Subs    []Spec // 子命令 Run为空时该命令仅作为子命令分组
+}
+
+// Flag 类型化flag声明 flag类型由Default的类型决定
+// 支持类型: string bool int int64 uint float64 time.Duration []string []int
+type Flag struct {
+	Name       string
+	Shorthand  string
+	Usage      string
+	Default    any
+	Required   bool
+	Persistent bool // 是否对子命令生效
+}
+
+// Context 命令运行上下文
+type Context struct {
+	context.Context
+	Cmd  *cobra.Command
+	Args []string
+}
+
+// String 获取string类型flag值
+func (c *Context) String(name string) string {
+	v, _ := c.Cmd.Flags().GetString(name)
+	return v
+}
+
+// Bool 获取bool类型flag值
+func (c *Context) Bool(name string) bool {
+	v, _ := c.Cmd.Flags().GetBool(name)
+	return v
+}
+
+// Int 获取int类型flag值
+func (c *Context) Int(name string) int {
+	v, _ := c.Cmd.Flags().GetInt(name)
+	return v
+}
+
+// Int64 获取int64类型flag值
+func (c *Context) Int64(name string) int64 {
+	v, _ := c.Cmd.Flags().GetInt64(name)
+	return v
+}
+
+// Uint 获取uint类型flag值
+func (c *Context) Uint(name string) uint {
+	v, _ := c.Cmd.Flags().GetUint(name)
+	return v
+}
+
+// Float64 获取float64类型flag值
+func (c *Context) Float64(name string) float64 {
+	v, _ := c.Cmd.Flags().GetFloat64(name)
+	return v
+}
+
+// Duration 获取time.Duration类型flag值
+func (c *Context) Duration(name string) time.Duration {
+	v, _ := c.Cmd.Flags().GetDuration(name)
+	return v
+}
+
+// StringSlice 获取[]string类型flag值
+func (c *Context) StringSlice(name string) []string {
+	v, _ := c.Cmd.Flags().GetStringSlice(name)
+	return v
+}
+
+// IntSlice 获取[]int类型flag值
+func (c *Context) IntSlice(name string) []int {
+	v, _ := c.Cmd.Flags().GetIntSlice(name)
+	return v
+}
+
+// Changed 判断flag是否在命令行中指定
+func (c *Context) Changed(name string) bool {
+	return c.Cmd.Flags().Changed(name)
+}
+
+var (
+	specMu sync.RWMutex
+	specs  = map[string]Spec{}
+)
+
+// Register 注册命令 支持类型化flag与多级子命令
+func Register(specList ...Spec) {
+	for _, spec := range specList {
+		root.AddCommand(build(root, spec, ""))
+	}
+}
+
+// Lookup 获取已注册的命令声明
+// 参数:
+// path: 命令路径 多级命令以空格分隔 例: "user import"
+func Lookup(path string) (Spec, bool) {
+	specMu.RLock()
+	defer specMu.RUnlock()
+	spec, ok := specs[path]
+	return spec, ok
+}
+
+// build 以命令声明创建cobra命令 并记录到命令声明列表
+func build(parent *cobra.Command, spec Spec, prefix string) *cobra.Command {
+	name, _, _ := strings.Cut(strings.TrimSpace(spec.Use), " ")
+	path := strings.TrimSpace(prefix + " " + name)
+	if spec.Short == "" {
+		spec.Short = fmt.Sprintf("运行命令[%s]", path)
+	}
+	if spec.Long == "" {
+		spec.Long = spec.Short
+	}
+
+	cmd := newCommand(spec)
+	if spec.Group != "" {
+		if !parent.ContainsGroup(spec.Group) {
+			parent.AddGroup(&cobra.Group{ID: spec.Group, Title: spec.Group})
+		}
+		cmd.GroupID = spec.Group
+	}
+	if spec.Run != nil {
+		runner := spec.runner()
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return bootAndRun(runner, cmd, args)
+		}
+	}
+	for _, sub := range spec.Subs {
+		cmd.AddCommand(build(cmd, sub, path))
+	}
+
+	specMu.Lock()
+	specs[path] = spec
+	specMu.Unlock()
+	return cmd
+}
+
+// newCommand 以命令声明创建不含子命令与运行逻辑的cobra命令
+func newCommand(spec Spec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     spec.Use,
+		Aliases: spec.Aliases,
+		Short:   spec.Short,
+		Long:    spec.Long,
+		Example: spec.Example,
+		Args:    spec.Args,
+	}
+	for _, f := range spec.Flags {
+		addFlag(cmd, f)
+	}
+	return cmd
+}
+
+// runner 转换为 RunE 可执行的运行器
+func (s Spec) runner() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return s.Run(&Context{Context: ctx, Cmd: cmd, Args: args})
+	}
+}
+
+// addFlag 按默认值类型声明flag 类型不支持时panic
+func addFlag(cmd *cobra.Command, f Flag) {
+	fs := cmd.Flags()
+	if f.Persistent {
+		fs = cmd.PersistentFlags()
+	}
+	switch v := f.Default.(type) {
+	case string:
+		fs.StringP(f.Name, f.Shorthand, v, f.Usage)
+	case bool:
+		fs.BoolP(f.Name, f.Shorthand, v, f.Usage)
+	case int:
+		fs.IntP(f.Name, f.Shorthand, v, f.Usage)
+	case int64:
+		fs.Int64P(f.Name, f.Shorthand, v, f.Usage)
+	case uint:
+		fs.UintP(f.Name, f.Shorthand, v, f.Usage)
+	case float64:
+		fs.Float64P(f.Name, f.Shorthand, v, f.Usage)
+	case time.Duration:
+		fs.DurationP(f.Name, f.Shorthand, v, f.Usage)
+	case []string:
+		fs.StringSliceP(f.Name, f.Shorthand, v, f.Usage)
+	case []int:
+		fs.IntSliceP(f.Name, f.Shorthand, v, f.Usage)
+	case nil:
+		fs.StringP(f.Name, f.Shorthand, "", f.Usage)
+	default:
+		panic(fmt.Sprintf("命令[%s]的flag[%s]类型[%T]不支持", cmd.Use, f.Name, f.Default))
+	}
+	if f.Required {
+		if f.Persistent {
+			_ = cmd.MarkPersistentFlagRequired(f.Name)
+		} else {
+			_ = cmd.MarkFlagRequired(f.Name)
+		}
+	}
+}
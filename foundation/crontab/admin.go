@@ -0,0 +1,77 @@
+package crontab
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zjutjh/mygo/foundation/reply"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// AdminRegister 注册定时任务管理接口
+// GET  {router}/jobs                任务列表 含调度表达式、上次/下次调度时间、最近一次运行结果与暂停状态
+// GET  {router}/jobs/:name/history 任务运行历史 ?limit=20
+// POST {router}/jobs/:name/trigger  立即运行一次任务 (需与定时任务服务运行在同一进程)
+// POST {router}/jobs/:name/pause    暂停任务
+// POST {router}/jobs/:name/resume   恢复任务
+// 例: crontab.AdminRegister(engine.Group("/admin/cron", auth))
+func AdminRegister(router gin.IRouter) {
+	router.GET("/jobs", func(ctx *gin.Context) {
+		list, err := Status(ctx)
+		if err != nil {
+			adminFail(ctx, err)
+			return
+		}
+		reply.Success(ctx, list)
+	})
+	router.GET("/jobs/:name/history", func(ctx *gin.Context) {
+		name := ctx.Param("name")
+		if _, ok := Lookup(name); !ok {
+			reply.Fail(ctx, kit.CodeDataNotFound)
+			return
+		}
+		limit, _ := strconv.Atoi(ctx.Query("limit"))
+		list, err := History(ctx, name, limit)
+		if err != nil {
+			adminFail(ctx, err)
+			return
+		}
+		reply.Success(ctx, list)
+	})
+	router.POST("/jobs/:name/trigger", func(ctx *gin.Context) {
+		if err := Trigger(ctx.Param("name")); err != nil {
+			adminFail(ctx, err)
+			return
+		}
+		reply.Success(ctx, nil)
+	})
+	router.POST("/jobs/:name/pause", func(ctx *gin.Context) {
+		if err := Pause(ctx, ctx.Param("name")); err != nil {
+			adminFail(ctx, err)
+			return
+		}
+		reply.Success(ctx, nil)
+	})
+	router.POST("/jobs/:name/resume", func(ctx *gin.Context) {
+		if err := Resume(ctx, ctx.Param("name")); err != nil {
+			adminFail(ctx, err)
+			return
+		}
+		reply.Success(ctx, nil)
+	})
+}
+
+// adminFail 管理接口错误响应
+func adminFail(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	switch {
+	case errors.Is(err, kit.ErrNotFound):
+		reply.Fail(ctx, kit.CodeDataNotFound)
+	case errors.Is(err, ErrNotRunning):
+		reply.Fail(ctx, kit.CodeServiceMaintenance)
+	default:
+		reply.Fail(ctx, kit.CodeRedisError)
+	}
+}
@@ -0,0 +1,120 @@
+package crontab
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// CatchUpMode 错过调度的补偿模式
+type CatchUpMode string
+
+const (
+	CatchUpNone   CatchUpMode = "none"   // CatchUpNone 不补偿 (默认)
+	CatchUpLatest CatchUpMode = "latest" // CatchUpLatest 仅补偿最近一次错过的调度
+	CatchUpAll    CatchUpMode = "all"    // CatchUpAll 补偿全部错过的调度 最多 Max 次
+)
+
+// defaultCatchUpMax CatchUpAll模式默认最多补偿次数
+const defaultCatchUpMax = 10
+
+// maxMissedScan 检测错过的调度时最多遍历的调度次数
+const maxMissedScan = 1000000
+
+// CatchUp 启动时对停机期间错过调度的补偿策略 依赖nedis中记录的最近调度时刻
+// 多副本部署时建议与集群单例一同使用 以调度时刻加锁避免重复补偿
+type CatchUp struct {
+	Mode CatchUpMode `mapstructure:"mode"` // Mode 补偿模式
+	Max  int         `mapstructure:"max"`  // Max CatchUpAll模式最多补偿次数 补偿最近的Max次 默认10
+}
+
+// validate 校验补偿模式
+func (m CatchUpMode) validate() error {
+	switch m {
+	case "", CatchUpNone, CatchUpLatest, CatchUpAll:
+		return nil
+	}
+	return fmt.Errorf("%w: 错过调度补偿模式[%s]不支持", kit.ErrDataFormat, m)
+}
+
+// catchUp 检测停机期间错过的调度并按补偿策略依次补偿运行
+// 首次启动 (未记录调度时刻) 时记录启动时刻作为检测起点
+func (j Job) catchUp(ctx context.Context, now time.Time) {
+	p := j.policy().CatchUp
+	if p.Mode == "" || p.Mode == CatchUpNone {
+		return
+	}
+	// 最近调度时刻始终记录于状态存储 不受运行历史配置影响
+	rdb, err := stateRedis()
+	if err != nil {
+		jobLogger.WithField("job", j.Name).WithError(err).Error("定时任务配置了错过调度补偿但无法读取最近调度时刻, 跳过补偿")
+		return
+	}
+
+	lastTick, _, err := lastState(ctx, rdb, j.Name)
+	if err != nil {
+		jobLogger.WithField("job", j.Name).WithError(err).Error("获取定时任务最近调度时刻错误, 跳过补偿")
+		return
+	}
+	if lastTick.IsZero() {
+		saveTick(j.Name, now.Truncate(time.Second))
+		return
+	}
+	lastTick = lastTick.In(j.location())
+
+	missed := j.missed(lastTick, now, p)
+	if len(missed) == 0 {
+		return
+	}
+	if paused, err := IsPaused(ctx, j.Name); err != nil || paused {
+		jobLogger.WithField("job", j.Name).Infof("定时任务[%s]已暂停或暂停状态未知, 跳过补偿", j.Name)
+		return
+	}
+
+	for _, tick := range missed {
+		if ctx.Err() != nil {
+			return
+		}
+		jobLogger.WithField("job", j.Name).WithField("tick", tick.Format(time.DateTime)).
+			Infof("定时任务[%s]补偿运行错过的调度", j.Name)
+		_ = j.execute(ctx, tick)
+	}
+}
+
+// catchUpAsync 在独立goroutine中补偿错过的调度 发生panic时记录日志并发送报警 不影响进程
+func (j Job) catchUpAsync(ctx context.Context, now time.Time) {
+	inflight.Add(1)
+	go func() {
+		defer inflight.Done()
+		defer j.recoverPanic()
+		j.catchUp(ctx, now)
+	}()
+}
+
+// missed 获取 (lastTick, now) 区间内错过的调度时刻 按补偿策略截取
+func (j Job) missed(lastTick, now time.Time, p CatchUp) []time.Time {
+	sched, err := parser.Parse(j.schedule())
+	if err != nil {
+		return nil
+	}
+	limit := 1
+	if p.Mode == CatchUpAll {
+		limit = p.Max
+		if limit <= 0 {
+			limit = defaultCatchUpMax
+		}
+	}
+
+	res := make([]time.Time, 0, limit)
+	t := sched.Next(lastTick)
+	for i := 0; i < maxMissedScan && !t.IsZero() && t.Before(now); i++ {
+		res = append(res, t)
+		if len(res) > limit {
+			res = res[1:]
+		}
+		t = sched.Next(t)
+	}
+	return res
+}
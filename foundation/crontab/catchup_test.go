@@ -0,0 +1,98 @@
+package crontab
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobMissed(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	j := Job{Name: "job", Spec: "0 0 * * * *", Location: "UTC"}
+
+	tests := []struct {
+		name     string
+		lastTick time.Time
+		now      time.Time
+		policy   CatchUp
+		want     []time.Time
+	}{
+		{
+			name:     "nothing missed",
+			lastTick: at(10, 0),
+			now:      at(10, 30),
+			policy:   CatchUp{Mode: CatchUpAll},
+			want:     []time.Time{},
+		},
+		{
+			name:     "firing at now is not missed",
+			lastTick: at(10, 0),
+			now:      at(11, 0),
+			policy:   CatchUp{Mode: CatchUpAll},
+			want:     []time.Time{},
+		},
+		{
+			name:     "latest keeps the most recent firing",
+			lastTick: at(10, 0),
+			now:      at(13, 30),
+			policy:   CatchUp{Mode: CatchUpLatest},
+			want:     []time.Time{at(13, 0)},
+		},
+		{
+			name:     "all keeps every firing within max",
+			lastTick: at(10, 0),
+			now:      at(13, 30),
+			policy:   CatchUp{Mode: CatchUpAll, Max: 5},
+			want:     []time.Time{at(11, 0), at(12, 0), at(13, 0)},
+		},
+		{
+			name:     "all keeps the most recent max firings",
+			lastTick: at(10, 0),
+			now:      at(15, 30),
+			policy:   CatchUp{Mode: CatchUpAll, Max: 2},
+			want:     []time.Time{at(14, 0), at(15, 0)},
+		},
+		{
+			name:     "all defaults max",
+			lastTick: at(0, 0),
+			now:      at(23, 30),
+			policy:   CatchUp{Mode: CatchUpAll},
+			want: []time.Time{
+				at(14, 0), at(15, 0), at(16, 0), at(17, 0), at(18, 0),
+				at(19, 0), at(20, 0), at(21, 0), at(22, 0), at(23, 0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := j.missed(tt.lastTick, tt.now, tt.policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("missed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobMissedLocation(t *testing.T) {
+	loc, err := loadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 每日9点 (Asia/Shanghai) 即UTC 1点
+	j := Job{Name: "job", Spec: "0 0 9 * * *", Location: "Asia/Shanghai"}
+	lastTick := time.Date(2024, 1, 1, 9, 0, 0, 0, loc)
+	now := time.Date(2024, 1, 3, 3, 0, 0, 0, time.UTC)
+
+	got := j.missed(lastTick, now, CatchUp{Mode: CatchUpAll})
+	want := []time.Time{time.Date(2024, 1, 2, 9, 0, 0, 0, loc), time.Date(2024, 1, 3, 9, 0, 0, 0, loc)}
+	if len(got) != len(want) {
+		t.Fatalf("missed = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("missed = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package crontab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjutjh/mygo/foundation/command"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// Command 定时任务命令声明 供外部调度器 (Kubernetes CronJob、systemd timer等) 单次运行任务
+// cron run <job-name>  运行一次指定任务 应用超时、集群单例锁、重试与报警等任务策略 失败时以非0状态码退出
+// cron list            输出全部任务及其状态
+// 例: command.Register(crontab.Command())
+func Command() command.Spec {
+	return command.Spec{
+		Use:   "cron",
+		Short: "定时任务命令",
+		Subs: []command.Spec{
+			{
+				Use:   "run <job-name>",
+				Short: "运行一次指定定时任务",
+				Long:  "引导应用并运行一次指定定时任务 应用超时、集群单例锁、重试与报警等任务策略 任务失败时以非0状态码退出",
+				Args:  cobra.ExactArgs(1),
+				Flags: []command.Flag{
+					{Name: "force", Default: false, Usage: "ignore paused state"},
+				},
+				Run: func(ctx *command.Context) error {
+					return RunOnce(ctx, ctx.Args[0], ctx.Bool("force"))
+				},
+			},
+			{
+				Use:   "list",
+				Short: "查看定时任务列表",
+				Args:  cobra.NoArgs,
+				Run: func(ctx *command.Context) error {
+					if _, err := prepare(); err != nil {
+						return err
+					}
+					list, err := Status(ctx)
+					if err != nil {
+						return err
+					}
+					out, err := json.MarshalIndent(list, "", "  ")
+					if err != nil {
+						return fmt.Errorf("%w: 序列化任务列表错误: %w", kit.ErrDataMarshal, err)
+					}
+					fmt.Fprintln(os.Stdout, string(out))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// RunOnce 在当前进程中运行一次指定任务 收到结束信号时取消任务ctx 应用需已完成引导
+// 参数:
+// name: 任务名
+// force: 是否忽略暂停状态
+func RunOnce(ctx context.Context, name string, force bool) error {
+	if _, err := prepare(); err != nil {
+		return err
+	}
+	j, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: 定时任务[%s]", kit.ErrNotFound, name)
+	}
+	if err := checkSingletons(j); err != nil {
+		return err
+	}
+
+	if !force {
+		paused, err := IsPaused(ctx, name)
+		if err != nil {
+			return fmt.Errorf("获取定时任务[%s]暂停状态错误: %w", name, err)
+		}
+		if paused {
+			jobLogger.WithField("job", name).Infof("定时任务[%s]已暂停, 跳过运行", name)
+			return nil
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return j.execute(ctx, j.now())
+}
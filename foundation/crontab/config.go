@@ -1,9 +1,24 @@
 package crontab
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var DefaultConfig = Config{
 	ShutdownWaitTimeout: 10 * time.Second,
+	Logger:              "",
+	Redis:               "redis",
+	Location:            "",
+
+	Lock: LockConfig{
+		Scope: "lock",
+		TTL:   time.Minute,
+	},
+	History: HistoryConfig{
+		Size: 100,
+	},
 
 	Log: LogConfig{
 		ErrorFilename: "./logs/cron.log",
@@ -17,10 +32,64 @@ var DefaultConfig = Config{
 
 type Config struct {
 	ShutdownWaitTimeout time.Duration `mapstructure:"shutdown_wait_timeout"`
+	Logger              string        `mapstructure:"logger"`   // Logger 任务运行日志使用的nlog实例scope
+	Redis               string        `mapstructure:"redis"`    // Redis 存储任务暂停状态等共享状态的nedis实例scope
+	Location            string        `mapstructure:"location"` // Location 默认调度时区 IANA时区名 例: "Asia/Shanghai" 为空时取进程本地时区
 
+	History  HistoryConfig               `mapstructure:"history"`
+	Lock     LockConfig                  `mapstructure:"lock"`
+	Policies map[string]PolicyConfig     `mapstructure:"policies"` // Policies 按任务名覆盖任务策略
+	Jobs     map[string]CommandJobConfig `mapstructure:"jobs"`     // Jobs 以配置声明的命令任务 键为任务名
+
+	// Deprecated: cron日志已改为输出到 Logger 指定的nlog实例 该配置不再生效
 	Log LogConfig `mapstructure:"log"`
 }
 
+// Validate 校验配置
+func (c Config) Validate() error {
+	if _, err := loadLocation(c.Location); err != nil {
+		return err
+	}
+	if c.Lock.TTL <= 0 {
+		return errors.New("集群单例任务锁有效期[lock.ttl]必须大于0")
+	}
+	for name, p := range c.Policies {
+		if err := p.Overlap.validate(); err != nil {
+			return fmt.Errorf("任务[%s]策略配置错误: %w", name, err)
+		}
+		if err := p.CatchUp.Mode.validate(); err != nil {
+			return fmt.Errorf("任务[%s]策略配置错误: %w", name, err)
+		}
+		if p.Retry.MaxAttempts < 0 {
+			return fmt.Errorf("任务[%s]最大运行次数[retry.max_attempts]不能为负数", name)
+		}
+	}
+	for name, j := range c.Jobs {
+		if j.Disabled {
+			continue
+		}
+		if j.Spec == "" || j.Command == "" {
+			return fmt.Errorf("任务[%s]未配置调度表达式[spec]或命令[command]", name)
+		}
+		if _, err := loadLocation(j.Location); err != nil {
+			return fmt.Errorf("任务[%s]: %w", name, err)
+		}
+		if j.Timeout < 0 {
+			return fmt.Errorf("任务[%s]超时时间[timeout]不能为负数", name)
+		}
+	}
+	return nil
+}
+
+type HistoryConfig struct {
+	Size int `mapstructure:"size"` // Size 每个任务在nedis中保留的运行记录数 为0时不记录运行历史
+}
+
+type LockConfig struct {
+	Scope string        `mapstructure:"scope"` // Scope 集群单例任务使用的lock实例scope
+	TTL   time.Duration `mapstructure:"ttl"`   // TTL 集群单例任务锁有效期 任务运行期间自动续期
+}
+
 type LogConfig struct {
 	ErrorFilename string `mapstructure:"error_filename"` // ErrorFilename 日志文件名
 	MaxSize       int    `mapstructure:"max_size"`       // MaxSize 触发日志切割大小 单位 MB
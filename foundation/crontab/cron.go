@@ -1,76 +1,153 @@
 package crontab
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"runtime"
 	"time"
 
-	"gopkg.in/natefinch/lumberjack.v2"
-
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/zjutjh/mygo/config"
 	"github.com/zjutjh/mygo/feishu"
 	"github.com/zjutjh/mygo/foundation/kernel"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nlog"
 )
 
 // CommandRegister 启动定时任务命令注册
+// 通过 Register 注册的任务会自动调度 jobRegister 用于注册原始cron任务 可为nil
 func CommandRegister(jobRegister func(c *cron.Cron)) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		Run(jobRegister)
-		return nil
+		return RunE(jobRegister)
 	}
 }
 
-// Run 启动定时任务
+// Run 启动定时任务 发生错误时退出进程
 func Run(jobRegister func(c *cron.Cron)) {
-	// 获取配置
-	conf := DefaultConfig
-	config.Pick().UnmarshalKey("cron", &conf)
-
-	_, err := os.OpenFile(conf.Log.ErrorFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		fmt.Fprintln(os.Stdout, "初始化cron引擎日志错误:", err)
+	if err := RunE(jobRegister); err != nil {
+		fmt.Fprintln(os.Stdout, "Cron运行错误:", err)
 		os.Exit(1)
 	}
-	ew := &lumberjack.Logger{
-		Filename:   conf.Log.ErrorFilename,
-		MaxSize:    conf.Log.MaxSize,
-		MaxAge:     conf.Log.MaxAge,
-		MaxBackups: conf.Log.MaxBackups,
-		LocalTime:  conf.Log.LocalTime,
-		Compress:   conf.Log.Compress,
+}
+
+// RunE 启动定时任务 返回运行过程中发生的错误
+func RunE(jobRegister func(c *cron.Cron)) error {
+	if err := kernel.Serve(NewRunnable(jobRegister)); err != nil {
+		return fmt.Errorf("%w: %w", kit.ErrServerRun, err)
+	}
+	return nil
+}
+
+// NewRunnable 创建可被内核托管的定时任务组件
+func NewRunnable(jobRegister func(c *cron.Cron)) kernel.Runnable {
+	return kernel.NewRunnable("cron", func(ctx context.Context) error {
+		return serve(ctx, jobRegister)
+	})
+}
+
+// serve 运行定时任务 直至ctx结束后优雅关闭
+func serve(ctx context.Context, jobRegister func(c *cron.Cron)) error {
+	// 获取配置并初始化运行环境
+	conf, err := prepare()
+	if err != nil {
+		return err
 	}
-	logger := cron.PrintfLogger(log.New(ew, "\ncron: ", log.LstdFlags))
 
 	// 初始化cron实例
-	c := cron.New(cron.WithSeconds(), cron.WithChain(Recover(logger)))
+	loc, err := loadLocation(conf.Location)
+	if err != nil {
+		return err
+	}
+	if err := checkSingletons(Jobs()...); err != nil {
+		return err
+	}
+	c := cron.New(cron.WithSeconds(), cron.WithLocation(loc), cron.WithLogger(logger), cron.WithChain(Recover(logger)))
+
+	// 调度已注册任务
+	entries := map[string]cron.EntryID{}
+	for _, j := range Jobs() {
+		id, err := c.AddJob(j.schedule(), j.cronJob(ctx))
+		if err != nil {
+			return fmt.Errorf("调度定时任务[%s]错误: %w", j.Name, err)
+		}
+		entries[j.Name] = id
+	}
 
 	// 启动cron
+	setScheduler(ctx, c, entries)
+	defer setScheduler(nil, nil, nil)
 	c.Start()
 
+	// 补偿停机期间错过的调度
+	now := time.Now()
+	for _, j := range Jobs() {
+		j.catchUpAsync(ctx, now)
+	}
+
 	// 注册任务
-	jobRegister(c)
-
-	// 监听并等待关闭服务
-	kernel.ListenStop(func() error {
-		ctx := c.Stop()
-		timer := time.NewTimer(conf.ShutdownWaitTimeout)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			fmt.Fprintln(os.Stdout, "Cron关闭完成")
-			return nil
-		case <-timer.C:
-			return errors.New("cron等待优雅处理超时, 强制关闭")
-		}
-	})
+	if jobRegister != nil {
+		jobRegister(c)
+	}
+
+	// 等待关闭服务
+	<-ctx.Done()
+	stopCtx := c.Stop()
+	done := make(chan struct{})
+	go func() {
+		<-stopCtx.Done()
+		inflight.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(conf.ShutdownWaitTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		fmt.Fprintln(os.Stdout, "Cron关闭完成")
+		return nil
+	case <-timer.C:
+		return errors.New("cron等待优雅处理超时, 强制关闭")
+	}
 }
 
+// prepare 获取配置 声明配置中的命令任务并初始化日志
+func prepare() (Config, error) {
+	conf, err := getConf()
+	if err != nil {
+		return conf, err
+	}
+	config.Track("cron", "cron", conf)
+
+	if err := declare(conf); err != nil {
+		return conf, err
+	}
+
+	jobLogger = nlog.Pick(conf.Logger)
+	logger = nlogLogger{l: jobLogger}
+	setConf(conf)
+	return conf, nil
+}
+
+func init() {
+	config.TrackFunc("cron", "cron", func() (any, error) { return getConf() })
+}
+
+// getConf 获取config.yaml[cron]配置
+func getConf() (Config, error) {
+	conf := DefaultConfig
+	if err := config.UnmarshalKeyStrict(config.Pick(), "cron", &conf); err != nil {
+		return conf, fmt.Errorf("%w: 解析config.yaml[cron]错误: %w", kit.ErrDataUnmarshal, err)
+	}
+	if err := conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[cron]错误: %w", kit.ErrConfigInvalid, err)
+	}
+	return conf, nil
+}
+
+// Recover 任务panic恢复包装器 记录panic日志并发送报警
 func Recover(logger cron.Logger) cron.JobWrapper {
 	return func(j cron.Job) cron.Job {
 		return cron.FuncJob(func() {
@@ -84,20 +161,8 @@ func Recover(logger cron.Logger) cron.JobWrapper {
 						err = fmt.Errorf("%v", r)
 					}
 					logger.Error(err, "panic", "stack", "...\n"+string(buf))
-					// 发送报警
-					if !feishu.Exist() {
-						return
-					}
-					go func() {
-						defer func() {
-							if err2 := recover(); err2 != nil {
-								log.Println("请求飞书Bot发送报警发生了Panic", err2)
-							}
-						}()
-						title := fmt.Sprintf("[%s]CronJob Panic!!!", config.AppName())
-						message := fmt.Sprintf("请注意: CronJob[%#v]发生了Panic!!!\nPanic: %#v", j, r)
-						feishu.Pick().Send(title, message)
-					}()
+					feishu.Alert(fmt.Sprintf("[%s]CronJob Panic!!!", config.AppName()),
+						fmt.Sprintf("请注意: CronJob[%#v]发生了Panic!!!\nPanic: %#v", j, r))
 				}
 			}()
 			j.Run()
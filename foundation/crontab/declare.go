@@ -0,0 +1,75 @@
+package crontab
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zjutjh/mygo/foundation/command"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// CommandJobConfig config.yaml[cron.jobs.{name}] 以配置声明的命令任务 定时运行通过 command.Add 或 command.Register 注册的命令
+type CommandJobConfig struct {
+	Spec      string        `mapstructure:"spec"`      // Spec 调度表达式 秒级 例: "0 0 3 * * *"
+	Command   string        `mapstructure:"command"`   // Command 命令路径 多级命令以空格分隔 例: "user import"
+	Args      []string      `mapstructure:"args"`      // Args 命令行参数 可包含flag 例: ["a.csv", "--batch=100"]
+	Disabled  bool          `mapstructure:"disabled"`  // Disabled 是否停用
+	Timeout   time.Duration `mapstructure:"timeout"`   // Timeout 单次运行超时时间 为0时不限制
+	Singleton bool          `mapstructure:"singleton"` // Singleton 是否为集群单例任务
+	Location  string        `mapstructure:"location"`  // Location 调度时区 默认取 config.yaml[cron.location]
+}
+
+// declared 以配置声明的命令任务
+var declared []Job
+
+// declare 以 config.yaml[cron.jobs] 声明命令任务 替换此前声明的全部命令任务
+func declare(conf Config) error {
+	names := make([]string, 0, len(conf.Jobs))
+	for name := range conf.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]Job, 0, len(names))
+	for _, name := range names {
+		jc := conf.Jobs[name]
+		if jc.Disabled {
+			continue
+		}
+		if spec, ok := command.Lookup(jc.Command); !ok || spec.Run == nil {
+			return fmt.Errorf("%w: 定时任务[%s]的命令[%s]未注册", kit.ErrNotFound, name, jc.Command)
+		}
+		path, args := jc.Command, jc.Args
+		list = append(list, Job{
+			Name:      name,
+			Spec:      jc.Spec,
+			Timeout:   jc.Timeout,
+			Singleton: jc.Singleton,
+			Location:  jc.Location,
+			Meta:      map[string]string{"command": path},
+			Run: func(ctx context.Context) error {
+				return command.Invoke(ctx, path, args)
+			},
+		})
+	}
+
+	for _, d := range list {
+		if _, err := parser.Parse(d.schedule()); err != nil {
+			return fmt.Errorf("%w: 定时任务[%s]调度表达式[%s]错误: %w", kit.ErrDataFormat, d.Name, d.Spec, err)
+		}
+	}
+
+	jobMu.Lock()
+	defer jobMu.Unlock()
+	for _, d := range list {
+		for _, j := range jobs {
+			if j.Name == d.Name {
+				return fmt.Errorf("%w: 定时任务[%s]已在代码中注册", kit.ErrAlreadyExists, d.Name)
+			}
+		}
+	}
+	declared = list
+	return nil
+}
@@ -0,0 +1,159 @@
+package crontab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/feishu"
+)
+
+// historyTimeout 读写运行历史的超时时间
+const historyTimeout = 3 * time.Second
+
+// saveTickScript 仅在调度时刻晚于已记录的调度时刻时更新
+var saveTickScript = redis.NewScript(`
+local cur = redis.call("HGET", KEYS[1], "last_tick")
+if not cur or tonumber(cur) < tonumber(ARGV[1]) then
+	redis.call("HSET", KEYS[1], "last_tick", ARGV[1])
+end
+return 1
+`)
+
+// historyKey 任务运行历史的Redis Key
+func historyKey(name string) string {
+	return fmt.Sprintf("%s:cron:history:%s", config.AppName(), name)
+}
+
+// stateKey 任务运行状态的Redis Key 包含最近调度时刻与最近成功运行时间
+func stateKey(name string) string {
+	return fmt.Sprintf("%s:cron:state:%s", config.AppName(), name)
+}
+
+// historyRedis 获取存储运行历史的nedis实例 未开启运行历史或未加载nedis实例时返回false
+func historyRedis() (redis.UniversalClient, bool) {
+	if currentConf().History.Size <= 0 {
+		return nil, false
+	}
+	rdb, err := stateRedis()
+	if err != nil {
+		return nil, false
+	}
+	return rdb, true
+}
+
+// recordRun 记录任务运行结果 同时写入nedis运行历史
+func recordRun(name string, r RunRecord) {
+	stateMu.Lock()
+	lastRuns[name] = r
+	stateMu.Unlock()
+
+	rdb, ok := historyRedis()
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, historyKey(name), data)
+		pipe.LTrim(ctx, historyKey(name), 0, int64(currentConf().History.Size-1))
+		if r.Outcome == OutcomeSuccess {
+			pipe.HSet(ctx, stateKey(name), "last_success", r.End.UnixMilli())
+		}
+		return nil
+	})
+	if err != nil {
+		jobLogger.WithField("job", name).WithError(err).Error("记录定时任务运行历史错误")
+	}
+}
+
+// saveTick 记录任务最近已运行的调度时刻 用于启动时检测错过的调度
+func saveTick(name string, tick time.Time) {
+	rdb, err := stateRedis()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	if err := saveTickScript.Run(ctx, rdb, []string{stateKey(name)}, tick.Unix()).Err(); err != nil {
+		jobLogger.WithField("job", name).WithError(err).Error("记录定时任务调度时刻错误")
+	}
+}
+
+// History 获取任务最近的运行记录 按时间倒序排列 未开启运行历史时返回当前进程内最近一次运行记录
+func History(ctx context.Context, name string, limit int) ([]RunRecord, error) {
+	rdb, ok := historyRedis()
+	if !ok {
+		stateMu.RLock()
+		defer stateMu.RUnlock()
+		if r, ok := lastRuns[name]; ok {
+			return []RunRecord{r}, nil
+		}
+		return []RunRecord{}, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	items, err := rdb.LRange(ctx, historyKey(name), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]RunRecord, 0, len(items))
+	for _, item := range items {
+		r := RunRecord{}
+		if err := json.Unmarshal([]byte(item), &r); err != nil {
+			continue
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// lastState 获取任务最近调度时刻与最近成功运行时间 未记录时为零值
+func lastState(ctx context.Context, rdb redis.UniversalClient, name string) (lastTick, lastSuccess time.Time, err error) {
+	values, err := rdb.HMGet(ctx, stateKey(name), "last_tick", "last_success").Result()
+	if err != nil {
+		return lastTick, lastSuccess, err
+	}
+	if v, ok := values[0].(string); ok {
+		if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+			lastTick = time.Unix(sec, 0)
+		}
+	}
+	if v, ok := values[1].(string); ok {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			lastSuccess = time.UnixMilli(ms)
+		}
+	}
+	return lastTick, lastSuccess, nil
+}
+
+// alertJob 异步发送任务报警 附带任务最近成功运行时间
+func alertJob(name, title, message string) {
+	if !feishu.Exist() {
+		return
+	}
+	go func() {
+		if rdb, ok := historyRedis(); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+			defer cancel()
+			if _, lastSuccess, err := lastState(ctx, rdb, name); err == nil {
+				if lastSuccess.IsZero() {
+					message += "\n最近成功运行: 无记录"
+				} else {
+					message += "\n最近成功运行: " + lastSuccess.Format(time.DateTime)
+				}
+			}
+		}
+		feishu.Alert(title, message)
+	}()
+}
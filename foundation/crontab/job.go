@@ -0,0 +1,274 @@
+package crontab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// 任务运行结果
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailure  = "failure"
+	OutcomeTimeout  = "timeout"
+	OutcomeCanceled = "canceled"
+	OutcomePanic    = "panic"
+)
+
+// Job 定时任务声明
+type Job struct {
+	Name      string                          // Name 任务名 全局唯一
+	Spec      string                          // Spec 调度表达式 秒级 例: "0 */5 * * * *"
+	Run       func(ctx context.Context) error // Run 任务逻辑 ctx在服务关闭或运行超时时取消
+	Timeout   time.Duration                   // Timeout 单次运行超时时间 为0时不限制
+	Meta      map[string]string               // Meta 任务元数据 记录到运行日志
+	Singleton bool                            // Singleton 是否为集群单例任务 见 Singleton
+	Overlap   Overlap                         // Overlap 上次运行未结束时的重叠调度策略 默认 OverlapAllow
+	Retry     Retry                           // Retry 运行失败时的重试策略 默认不重试
+	CatchUp   CatchUp                         // CatchUp 启动时对停机期间错过调度的补偿策略 默认不补偿
+	Location  string                          // Location 调度时区 IANA时区名 例: "Asia/Shanghai" 默认取 config.yaml[cron.location]
+}
+
+// parser 与cron实例一致的秒级调度表达式解析器
+var parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+var (
+	jobMu sync.RWMutex
+	jobs  []Job
+)
+
+// Register 注册定时任务 任务在定时任务服务启动时调度
+func Register(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("%w: 定时任务名为空", kit.ErrDataFormat)
+	}
+	if job.Run == nil {
+		return fmt.Errorf("%w: 定时任务[%s]未设置运行逻辑", kit.ErrDataFormat, job.Name)
+	}
+	if _, err := loadLocation(job.Location); err != nil {
+		return fmt.Errorf("定时任务[%s]: %w", job.Name, err)
+	}
+	spec := job.Spec
+	if job.Location != "" && !hasTimezone(spec) {
+		spec = "CRON_TZ=" + job.Location + " " + spec
+	}
+	if _, err := parser.Parse(spec); err != nil {
+		return fmt.Errorf("%w: 定时任务[%s]调度表达式[%s]错误: %w", kit.ErrDataFormat, job.Name, job.Spec, err)
+	}
+	if err := job.Overlap.validate(); err != nil {
+		return fmt.Errorf("定时任务[%s]: %w", job.Name, err)
+	}
+	if err := job.CatchUp.Mode.validate(); err != nil {
+		return fmt.Errorf("定时任务[%s]: %w", job.Name, err)
+	}
+
+	jobMu.Lock()
+	defer jobMu.Unlock()
+	for _, j := range slices.Concat(jobs, declared) {
+		if j.Name == job.Name {
+			return fmt.Errorf("%w: 定时任务[%s]", kit.ErrAlreadyExists, job.Name)
+		}
+	}
+	jobs = append(jobs, job)
+	return nil
+}
+
+// MustRegister 注册定时任务 注册失败时panic
+func MustRegister(job Job) {
+	if err := Register(job); err != nil {
+		panic(err)
+	}
+}
+
+// Jobs 获取全部定时任务 依次为按注册顺序排列的代码注册任务与按任务名排列的配置声明任务
+func Jobs() []Job {
+	jobMu.RLock()
+	defer jobMu.RUnlock()
+	res := make([]Job, 0, len(jobs)+len(declared))
+	res = append(res, jobs...)
+	res = append(res, declared...)
+	return res
+}
+
+// Lookup 获取指定名称的定时任务
+func Lookup(name string) (Job, bool) {
+	for _, j := range Jobs() {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+// cronJob 转换为可被cron调度的任务 ctx为定时任务服务运行上下文 暂停中的任务跳过本次调度
+func (j Job) cronJob(ctx context.Context) cron.Job {
+	return cron.FuncJob(func() {
+		tick := j.scheduledTick()
+		paused, err := IsPaused(ctx, j.Name)
+		if err != nil {
+			jobLogger.WithField("job", j.Name).WithError(err).Error("获取定时任务暂停状态错误, 按未暂停处理")
+		}
+		if paused {
+			jobLogger.WithField("job", j.Name).Infof("定时任务[%s]已暂停, 跳过本次调度", j.Name)
+			return
+		}
+		_ = j.execute(ctx, tick)
+	})
+}
+
+// scheduledTick 获取本次调度的调度时刻 未在调度器中找到时取当前时刻
+func (j Job) scheduledTick() time.Time {
+	stateMu.RLock()
+	id, ok := entries[j.Name]
+	stateMu.RUnlock()
+	if ok {
+		if tick, ok := scheduledTick(func(e cron.Entry) bool { return e.ID == id }); ok {
+			return tick.In(j.location())
+		}
+	}
+	return j.now()
+}
+
+// execute 按任务策略运行任务指定调度时刻的一次调度 依次应用重叠调度策略、集群单例锁与重试策略 返回最后一次运行的错误
+func (j Job) execute(ctx context.Context, tick time.Time) (err error) {
+	// 每次调度使用独立的请求ID与链路ID 重试共用
+	ctx = kit.WithMeta(ctx, kit.NewMeta())
+	p := j.policy()
+	run := func() {
+		err = j.attempts(ctx, tick, p.Retry)
+		saveTick(j.Name, tick)
+	}
+	j.guard(ctx, p.Overlap, func() {
+		if !j.Singleton {
+			run()
+			return
+		}
+		conf := currentConf()
+		singleton(j.Name, tick, singletonOptions{scope: conf.Lock.Scope, ttl: conf.Lock.TTL}, run)
+	})
+	return err
+}
+
+// executeAsync 在独立goroutine中运行任务的一次调度 发生panic时记录日志并发送报警 不影响进程
+// 调度器运行的任务由 Recover 恢复 手动触发与补偿运行使用该方法
+func (j Job) executeAsync(ctx context.Context, tick time.Time) {
+	inflight.Add(1)
+	go func() {
+		defer inflight.Done()
+		defer j.recoverPanic()
+		_ = j.execute(ctx, tick)
+	}()
+}
+
+// recoverPanic 恢复任务goroutine中的panic 记录日志并发送报警 需以defer调用
+func (j Job) recoverPanic() {
+	if r := recover(); r != nil {
+		const size = 64 << 10
+		buf := make([]byte, size)
+		buf = buf[:runtime.Stack(buf, false)]
+		jobLogger.WithFields(logrus.Fields{"job": j.Name, "stack": string(buf)}).
+			Errorf("定时任务[%s]发生panic", j.Name)
+		alertJob(j.Name, fmt.Sprintf("[%s]CronJob Panic!!!", config.AppName()),
+			fmt.Sprintf("请注意: CronJob[%s]发生了Panic!!!\nPanic: %#v", j.Name, r))
+	}
+}
+
+// attempts 按重试策略运行任务
+func (j Job) attempts(ctx context.Context, tick time.Time, retry Retry) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = j.attempt(ctx, tick, attempt)
+		if err == nil || attempt >= retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		backoff := retry.backoff(attempt)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// attempt 运行一次任务 记录运行日志 发生panic时发送报警
+func (j Job) attempt(ctx context.Context, tick time.Time, attempt int) (err error) {
+	var cancel context.CancelFunc
+	if j.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	start := time.Now().In(tick.Location())
+	entry := jobLogger.WithContext(ctx).WithFields(logrus.Fields{
+		"job":     j.Name,
+		"spec":    j.Spec,
+		"tz":      tick.Location().String(),
+		"meta":    j.Meta,
+		"tick":    tick.Format(time.DateTime),
+		"start":   start.Format(time.DateTime),
+		"attempt": attempt,
+	})
+	outcome := OutcomeSuccess
+
+	defer func() {
+		if r := recover(); r != nil {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			outcome = OutcomePanic
+			err = fmt.Errorf("发生panic: %v", r)
+			entry = entry.WithField("stack", string(buf))
+			alertJob(j.Name, fmt.Sprintf("[%s]CronJob Panic!!!", config.AppName()),
+				fmt.Sprintf("请注意: CronJob[%s]发生了Panic!!!\nPanic: %#v", j.Name, r))
+		}
+
+		end := time.Now().In(tick.Location())
+		record := RunRecord{
+			Tick:     tick,
+			Attempt:  attempt,
+			Start:    start,
+			End:      end,
+			Duration: end.Sub(start).String(),
+			Outcome:  outcome,
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		recordRun(j.Name, record)
+
+		entry = entry.WithFields(logrus.Fields{
+			"duration": record.Duration,
+			"outcome":  outcome,
+		})
+		if err != nil {
+			entry.WithError(err).Errorf("定时任务[%s]运行失败", j.Name)
+			return
+		}
+		entry.Infof("定时任务[%s]运行成功", j.Name)
+	}()
+
+	err = j.Run(ctx)
+	if err != nil {
+		outcome = OutcomeFailure
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			outcome = OutcomeTimeout
+		case errors.Is(ctx.Err(), context.Canceled):
+			outcome = OutcomeCanceled
+		}
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package crontab
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据 保证精简容器镜像中可加载时区
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+var (
+	locationMu sync.Mutex
+	locations  = map[string]*time.Location{}
+)
+
+// loadLocation 加载IANA时区 例: "Asia/Shanghai" 为空时返回进程本地时区
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	locationMu.Lock()
+	defer locationMu.Unlock()
+	if loc, ok := locations[name]; ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 时区[%s]错误: %w", kit.ErrDataFormat, name, err)
+	}
+	locations[name] = loc
+	return loc, nil
+}
+
+// hasTimezone 判断调度表达式是否已通过 CRON_TZ= 或 TZ= 前缀指定时区
+func hasTimezone(spec string) bool {
+	return strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=")
+}
+
+// location 获取任务生效时区 依次取调度表达式时区前缀、任务声明、config.yaml[cron.location] 与进程本地时区
+func (j Job) location() *time.Location {
+	if hasTimezone(j.Spec) {
+		if s, err := parser.Parse(j.Spec); err == nil {
+			if ss, ok := s.(*cron.SpecSchedule); ok {
+				return ss.Location
+			}
+		}
+	}
+	name := j.Location
+	if name == "" {
+		name = currentConf().Location
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// schedule 获取带时区前缀的调度表达式
+func (j Job) schedule() string {
+	if hasTimezone(j.Spec) {
+		return j.Spec
+	}
+	loc := j.location()
+	if loc == time.Local {
+		return j.Spec
+	}
+	return "CRON_TZ=" + loc.String() + " " + j.Spec
+}
+
+// now 获取任务时区的当前调度时刻 (精确到秒)
+func (j Job) now() time.Time {
+	return time.Now().In(j.location()).Truncate(time.Second)
+}
@@ -0,0 +1,33 @@
+package crontab
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jobLogger 任务运行日志 启动时替换为 config.yaml[cron.logger] 指定的nlog实例
+var jobLogger = logrus.StandardLogger()
+
+// nlogLogger 以nlog实例实现 cron.Logger
+type nlogLogger struct {
+	l *logrus.Logger
+}
+
+// Info cron调度过程日志 以Debug级别记录 与cron默认的非verbose日志一致
+func (n nlogLogger) Info(msg string, keysAndValues ...any) {
+	n.l.WithFields(toFields(keysAndValues)).Debug("cron: " + msg)
+}
+
+func (n nlogLogger) Error(err error, msg string, keysAndValues ...any) {
+	n.l.WithFields(toFields(keysAndValues)).WithError(err).Error("cron: " + msg)
+}
+
+// toFields 将cron日志的键值对列表转换为日志字段
+func toFields(keysAndValues []any) logrus.Fields {
+	fields := logrus.Fields{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	return fields
+}
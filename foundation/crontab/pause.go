@@ -0,0 +1,76 @@
+package crontab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nedis"
+)
+
+// pausedKey 暂停任务集合的Redis Key
+func pausedKey() string {
+	return config.AppName() + ":cron:paused"
+}
+
+// stateRedis 获取存储任务共享状态的nedis实例 未加载时返回 kit.ErrNotFound
+func stateRedis() (redis.UniversalClient, error) {
+	scope := currentConf().Redis
+	if !nedis.Exist(scope) {
+		return nil, fmt.Errorf("%w: 未加载存储定时任务状态的nedis实例[%s]", kit.ErrNotFound, scope)
+	}
+	return nedis.Pick(scope), nil
+}
+
+// Pause 暂停指定任务 暂停状态存储于nedis 对全部实例生效且重启后保留
+func Pause(ctx context.Context, name string) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("%w: 定时任务[%s]", kit.ErrNotFound, name)
+	}
+	rdb, err := stateRedis()
+	if err != nil {
+		return err
+	}
+	return rdb.SAdd(ctx, pausedKey(), name).Err()
+}
+
+// Resume 恢复指定任务
+func Resume(ctx context.Context, name string) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("%w: 定时任务[%s]", kit.ErrNotFound, name)
+	}
+	rdb, err := stateRedis()
+	if err != nil {
+		return err
+	}
+	return rdb.SRem(ctx, pausedKey(), name).Err()
+}
+
+// IsPaused 判断指定任务是否暂停 未加载nedis实例时视为未暂停
+func IsPaused(ctx context.Context, name string) (bool, error) {
+	rdb, err := stateRedis()
+	if err != nil {
+		return false, nil
+	}
+	return rdb.SIsMember(ctx, pausedKey(), name).Result()
+}
+
+// pausedJobs 获取全部暂停中的任务 未加载nedis实例时返回空
+func pausedJobs(ctx context.Context) (map[string]bool, error) {
+	res := map[string]bool{}
+	rdb, err := stateRedis()
+	if err != nil {
+		return res, nil
+	}
+	names, err := rdb.SMembers(ctx, pausedKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		res[name] = true
+	}
+	return res, nil
+}
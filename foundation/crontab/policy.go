@@ -0,0 +1,132 @@
+package crontab
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// Overlap 任务上次运行未结束时的重叠调度策略
+type Overlap string
+
+const (
+	OverlapAllow Overlap = "allow" // OverlapAllow 允许并发运行 (默认)
+	OverlapSkip  Overlap = "skip"  // OverlapSkip 跳过本次调度
+	OverlapDelay Overlap = "delay" // OverlapDelay 等待上次运行结束后再运行
+)
+
+// Retry 任务运行失败时的重试策略 MaxAttempts不大于1时不重试
+// 第n次重试前等待 InitialBackoff * Multiplier^(n-1) 且不超过 MaxBackoff
+type Retry struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // MaxAttempts 最大运行次数 (含首次运行)
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // InitialBackoff 首次重试前等待时间 默认1s
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // MaxBackoff 重试前最大等待时间 默认1m
+	Multiplier     float64       `mapstructure:"multiplier"`      // Multiplier 等待时间增长倍数 默认2
+}
+
+// PolicyConfig config.yaml[cron.policies.{job}] 任务策略配置 非零值覆盖代码中的声明
+type PolicyConfig struct {
+	Overlap Overlap `mapstructure:"overlap"`
+	Retry   Retry   `mapstructure:"retry"`
+	CatchUp CatchUp `mapstructure:"catch_up"`
+}
+
+// validate 校验重叠调度策略
+func (o Overlap) validate() error {
+	switch o {
+	case "", OverlapAllow, OverlapSkip, OverlapDelay:
+		return nil
+	}
+	return fmt.Errorf("%w: 重叠调度策略[%s]不支持", kit.ErrDataFormat, o)
+}
+
+// backoff 第attempt次运行失败后的等待时间
+func (r Retry) backoff(attempt int) time.Duration {
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	return kit.Backoff(r.InitialBackoff, maxBackoff, r.Multiplier, attempt)
+}
+
+// policy 获取任务生效策略 config.yaml[cron.policies.{job}] 中的非零值覆盖代码中的声明
+func (j Job) policy() PolicyConfig {
+	res := PolicyConfig{Overlap: j.Overlap, Retry: j.Retry, CatchUp: j.CatchUp}
+	p, ok := currentConf().Policies[j.Name]
+	if !ok {
+		return res
+	}
+	if p.Overlap != "" {
+		res.Overlap = p.Overlap
+	}
+	if p.Retry.MaxAttempts != 0 {
+		res.Retry.MaxAttempts = p.Retry.MaxAttempts
+	}
+	if p.Retry.InitialBackoff != 0 {
+		res.Retry.InitialBackoff = p.Retry.InitialBackoff
+	}
+	if p.Retry.MaxBackoff != 0 {
+		res.Retry.MaxBackoff = p.Retry.MaxBackoff
+	}
+	if p.Retry.Multiplier != 0 {
+		res.Retry.Multiplier = p.Retry.Multiplier
+	}
+	if p.CatchUp.Mode != "" {
+		res.CatchUp.Mode = p.CatchUp.Mode
+	}
+	if p.CatchUp.Max != 0 {
+		res.CatchUp.Max = p.CatchUp.Max
+	}
+	return res
+}
+
+var (
+	slotMu sync.Mutex
+	slots  = map[string]chan struct{}{}
+)
+
+// slot 获取任务运行槽位 同一任务的全部调度与手动触发共用
+func slot(name string) chan struct{} {
+	slotMu.Lock()
+	defer slotMu.Unlock()
+	s, ok := slots[name]
+	if !ok {
+		s = make(chan struct{}, 1)
+		slots[name] = s
+	}
+	return s
+}
+
+// guard 按重叠调度策略运行任务
+func (j Job) guard(ctx context.Context, overlap Overlap, run func()) {
+	if overlap == "" || overlap == OverlapAllow {
+		run()
+		return
+	}
+
+	s := slot(j.Name)
+	switch overlap {
+	case OverlapSkip:
+		select {
+		case s <- struct{}{}:
+		default:
+			jobLogger.WithField("job", j.Name).Infof("定时任务[%s]上次运行未结束, 跳过本次调度", j.Name)
+			return
+		}
+	case OverlapDelay:
+		start := time.Now()
+		select {
+		case s <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		if waited := time.Since(start); waited > time.Second {
+			jobLogger.WithField("job", j.Name).Infof("定时任务[%s]等待上次运行结束[%s]后运行", j.Name, waited)
+		}
+	}
+	defer func() { <-s }()
+	run()
+}
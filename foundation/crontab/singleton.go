@@ -0,0 +1,146 @@
+package crontab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/lock"
+)
+
+type singletonOptions struct {
+	scope string
+	ttl   time.Duration
+}
+
+// SingletonOption 集群单例任务选项
+type SingletonOption func(o *singletonOptions)
+
+// WithLockScope 指定使用的lock实例scope 默认取 config.yaml[cron.lock.scope]
+func WithLockScope(scope string) SingletonOption {
+	return func(o *singletonOptions) {
+		o.scope = scope
+	}
+}
+
+// WithLockTTL 指定锁有效期 默认取 config.yaml[cron.lock.ttl]
+func WithLockTTL(ttl time.Duration) SingletonOption {
+	return func(o *singletonOptions) {
+		o.ttl = ttl
+	}
+}
+
+// Singleton 集群单例任务包装器 多副本部署时同一任务的同一次调度仅由一个实例执行
+// 锁以任务名与调度时刻区分 任务运行期间自动续期 执行结束后不主动释放 避免调度略有延迟的实例重复执行
+// 例: c.AddJob("0 */5 * * * *", cron.NewChain(crontab.Singleton("report")).Then(job))
+func Singleton(name string, opts ...SingletonOption) cron.JobWrapper {
+	return func(j cron.Job) cron.Job {
+		return &singletonJob{name: name, opts: opts, job: j}
+	}
+}
+
+// singletonJob 集群单例任务 以指针作为调度器条目中的任务 用于查找本次调度的调度时刻
+type singletonJob struct {
+	name string
+	opts []SingletonOption
+	job  cron.Job
+}
+
+func (s *singletonJob) Run() {
+	conf := currentConf()
+	o := singletonOptions{scope: conf.Lock.Scope, ttl: conf.Lock.TTL}
+	for _, opt := range s.opts {
+		opt(&o)
+	}
+	// 未在调度器中找到 (例: 包装后再被其他包装器包装) 时取当前时刻
+	tick, ok := scheduledTick(func(e cron.Entry) bool { return e.Job == cron.Job(s) })
+	if !ok {
+		tick = time.Now().Truncate(time.Second)
+	}
+	singleton(s.name, tick, o, s.job.Run)
+}
+
+// singleton 获取任务指定调度时刻的锁后运行 未获取到锁时跳过
+func singleton(name string, tick time.Time, o singletonOptions, run func()) {
+	release, err := acquire(name, tick, o)
+	if err != nil {
+		var taken *redsync.ErrTaken
+		if errors.Is(err, redsync.ErrFailed) || errors.As(err, &taken) {
+			jobLogger.WithFields(logrus.Fields{"job": name, "tick": tick.Format(time.DateTime)}).
+				Infof("定时任务[%s]本次调度已由其他实例执行, 跳过", name)
+			return
+		}
+		jobLogger.WithFields(logrus.Fields{"job": name, "tick": tick.Format(time.DateTime)}).WithError(err).
+			Errorf("定时任务[%s]获取集群单例锁错误, 跳过本次调度", name)
+		return
+	}
+	defer release()
+	run()
+}
+
+// acquire 获取任务本次调度的锁 并在任务运行期间自动续期 返回停止续期函数
+func acquire(name string, tick time.Time, o singletonOptions) (func(), error) {
+	if o.ttl <= 0 {
+		return nil, fmt.Errorf("任务[%s]锁有效期[%s]错误", name, o.ttl)
+	}
+	if err := checkLock(o.scope); err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("%s:cron:%s:%d", config.AppName(), name, tick.Unix())
+	mutex := lock.Pick(o.scope).NewMutex(key, redsync.WithExpiry(o.ttl), redsync.WithTries(1))
+	if err := mutex.TryLockContext(context.Background()); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(o.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if ok, err := mutex.ExtendContext(ctx); !ok && ctx.Err() == nil {
+					jobLogger.WithFields(logrus.Fields{"job": name, "tick": tick.Format(time.DateTime)}).WithError(err).
+						Errorf("定时任务[%s]集群单例锁续期错误", name)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// checkLock 检查集群单例锁使用的lock实例是否已加载
+func checkLock(scope string) error {
+	if !lock.Exist(scope) {
+		return fmt.Errorf("%w: 未加载集群单例锁使用的lock实例[%s]", kit.ErrNotFound, scope)
+	}
+	return nil
+}
+
+// checkSingletons 检查集群单例任务依赖的lock实例 避免运行时才发现未加载
+func checkSingletons(jobs ...Job) error {
+	scope := currentConf().Lock.Scope
+	for _, j := range jobs {
+		if !j.Singleton {
+			continue
+		}
+		if err := checkLock(scope); err != nil {
+			return fmt.Errorf("定时任务[%s]为集群单例任务: %w", j.Name, err)
+		}
+	}
+	return nil
+}
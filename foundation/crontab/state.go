@@ -0,0 +1,183 @@
+package crontab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// ErrNotRunning 定时任务服务未在当前进程运行
+var ErrNotRunning = errors.New("定时任务服务未运行")
+
+// RunRecord 任务单次运行记录
+type RunRecord struct {
+	Tick     time.Time `json:"tick"`
+	Attempt  int       `json:"attempt"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration string    `json:"duration"`
+	Outcome  string    `json:"outcome"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// JobStatus 任务状态
+type JobStatus struct {
+	Name        string            `json:"name"`
+	Spec        string            `json:"spec"`
+	Location    string            `json:"location"`
+	Timeout     string            `json:"timeout"`
+	Meta        map[string]string `json:"meta"`
+	Singleton   bool              `json:"singleton"`
+	Paused      bool              `json:"paused"`
+	LastSuccess *time.Time        `json:"last_success,omitempty"`
+	Prev        *time.Time        `json:"prev,omitempty"`
+	Next        *time.Time        `json:"next,omitempty"`
+	LastRun     *RunRecord        `json:"last_run,omitempty"`
+}
+
+var (
+	// logger cron调度与任务包装器日志 启动时替换为nlog实例
+	logger = cron.DefaultLogger
+
+	stateMu     sync.RWMutex
+	runtimeConf *Config
+	runCtx      context.Context
+	scheduler   *cron.Cron
+	entries     map[string]cron.EntryID
+	lastRuns    = map[string]RunRecord{}
+	// inflight 调度器之外运行中的任务 (手动触发与错过调度补偿) 关闭时一同等待
+	inflight sync.WaitGroup
+)
+
+// setScheduler 记录当前进程运行中的cron实例 c为nil时表示已停止
+func setScheduler(ctx context.Context, c *cron.Cron, ids map[string]cron.EntryID) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	runCtx = ctx
+	scheduler = c
+	entries = ids
+}
+
+// scheduledTick 获取调度器中匹配条目本次触发对应的调度时刻 调度器未运行或未找到匹配条目时返回false
+// 以调度时刻而非实际运行时刻区分每次调度 避免延迟启动的实例与其他实例得到不同的调度时刻
+func scheduledTick(match func(e cron.Entry) bool) (time.Time, bool) {
+	stateMu.RLock()
+	c := scheduler
+	stateMu.RUnlock()
+	if c == nil {
+		return time.Time{}, false
+	}
+	for _, e := range c.Entries() {
+		if match(e) && !e.Prev.IsZero() {
+			return e.Prev.Truncate(time.Second), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// setConf 记录生效配置
+func setConf(conf Config) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	runtimeConf = &conf
+}
+
+// currentConf 获取生效配置 定时任务服务未启动时读取 config.yaml[cron]
+func currentConf() Config {
+	stateMu.RLock()
+	conf := runtimeConf
+	stateMu.RUnlock()
+	if conf != nil {
+		return *conf
+	}
+	if !config.Exist("config") {
+		return DefaultConfig
+	}
+	c, err := getConf()
+	if err != nil {
+		return DefaultConfig
+	}
+	return c
+}
+
+// Status 获取全部任务的状态 上次/下次调度时间仅在定时任务服务运行的进程中可用
+// 开启运行历史时最近运行结果与最近成功运行时间取自nedis 否则取自当前进程
+func Status(ctx context.Context) ([]JobStatus, error) {
+	paused, err := pausedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := Jobs()
+	res := make([]JobStatus, 0, len(list))
+	for _, j := range list {
+		s := JobStatus{
+			Name:      j.Name,
+			Spec:      j.Spec,
+			Location:  j.location().String(),
+			Timeout:   j.Timeout.String(),
+			Meta:      j.Meta,
+			Singleton: j.Singleton,
+			Paused:    paused[j.Name],
+		}
+		stateMu.RLock()
+		if id, ok := entries[j.Name]; ok && scheduler != nil {
+			loc := j.location()
+			e := scheduler.Entry(id)
+			if !e.Prev.IsZero() {
+				prev := e.Prev.In(loc)
+				s.Prev = &prev
+			}
+			if !e.Next.IsZero() {
+				next := e.Next.In(loc)
+				s.Next = &next
+			}
+		}
+		stateMu.RUnlock()
+
+		records, err := History(ctx, j.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) != 0 {
+			r := records[0]
+			r.Tick, r.Start, r.End = r.Tick.In(j.location()), r.Start.In(j.location()), r.End.In(j.location())
+			s.LastRun = &r
+		}
+		if rdb, ok := historyRedis(); ok {
+			_, lastSuccess, err := lastState(ctx, rdb, j.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !lastSuccess.IsZero() {
+				lastSuccess = lastSuccess.In(j.location())
+				s.LastSuccess = &lastSuccess
+			}
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// Trigger 立即异步运行一次指定任务 忽略暂停状态 需在定时任务服务运行的进程中调用
+func Trigger(name string) error {
+	j, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: 定时任务[%s]", kit.ErrNotFound, name)
+	}
+	stateMu.RLock()
+	ctx := runCtx
+	stateMu.RUnlock()
+	if ctx == nil {
+		return ErrNotRunning
+	}
+	j.executeAsync(ctx, j.now())
+	return nil
+}
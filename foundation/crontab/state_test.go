@@ -0,0 +1,46 @@
+package crontab
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestScheduledTick(t *testing.T) {
+	c := cron.New(cron.WithSeconds())
+	got := make(chan time.Time, 1)
+	var id cron.EntryID
+	id, err := c.AddFunc("*/2 * * * * *", func() {
+		// 模拟实例延迟启动任务 调度时刻不随实际运行时刻变化
+		time.Sleep(1200 * time.Millisecond)
+		tick, ok := scheduledTick(func(e cron.Entry) bool { return e.ID == id })
+		if !ok {
+			t.Error("scheduledTick not found")
+		}
+		select {
+		case got <- tick:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setScheduler(context.Background(), c, map[string]cron.EntryID{"job": id})
+	defer setScheduler(nil, nil, nil)
+	c.Start()
+	defer c.Stop()
+
+	select {
+	case tick := <-got:
+		if late := time.Since(tick); late < 1200*time.Millisecond || late > 2*time.Second {
+			t.Fatalf("tick = %s, %s before now, want the scheduled firing time", tick, late)
+		}
+		if tick.Nanosecond() != 0 || tick.Second()%2 != 0 {
+			t.Fatalf("tick = %s, want the scheduled firing time", tick)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("job not run")
+	}
+}
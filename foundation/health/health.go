@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zjutjh/mygo/foundation/kernel"
+)
+
+// 检查状态
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker 组件健康检查器 返回nil表示组件健康
+type Checker func(ctx context.Context) error
+
+// Result 单个组件检查结果
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Latency  string `json:"latency"`
+	Error    string `json:"error,omitempty"`
+	Optional bool   `json:"optional,omitempty"` // Optional 非关键组件 检查失败不影响整体状态
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status     string   `json:"status"`
+	Stopping   bool     `json:"stopping"`
+	Components []Result `json:"components"`
+}
+
+// entry 已注册的检查器
+type entry struct {
+	checker  Checker
+	optional bool
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = map[string]entry{}
+)
+
+// Register 注册组件健康检查器 同名检查器将被覆盖
+func Register(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = entry{checker: checker}
+}
+
+// RegisterOptional 注册非关键组件健康检查器 结果仅供展示 检查失败不影响就绪状态
+// 适用于报警通道等不影响业务请求处理的组件
+func RegisterOptional(name string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = entry{checker: checker, optional: true}
+}
+
+// Live 存活检查 仅表示进程可正常响应 不执行组件检查器
+// 依赖组件故障不应导致进程被重启 组件检查由 Ready 执行
+func Live(ctx context.Context) Report {
+	return Report{
+		Status:     StatusUp,
+		Stopping:   kernel.Stopping(),
+		Components: []Result{},
+	}
+}
+
+// Ready 就绪检查 应用开始关闭后立即返回未就绪
+func Ready(ctx context.Context) Report {
+	if kernel.Stopping() {
+		return Report{
+			Status:     StatusDown,
+			Stopping:   true,
+			Components: []Result{},
+		}
+	}
+	return check(ctx)
+}
+
+// check 并发执行全部组件检查器并汇总结果
+func check(ctx context.Context) Report {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]Result, len(names))
+	wg := sync.WaitGroup{}
+	for i, name := range names {
+		mu.RLock()
+		e := checkers[name]
+		mu.RUnlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, name, e.checker)
+			results[i].Optional = e.optional
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusUp,
+		Stopping:   kernel.Stopping(),
+		Components: results,
+	}
+	for _, result := range results {
+		if result.Status != StatusUp && !result.Optional {
+			report.Status = StatusDown
+			break
+		}
+	}
+	return report
+}
+
+// run 执行单个检查器
+func run(ctx context.Context, name string, checker Checker) (result Result) {
+	start := time.Now()
+	result = Result{
+		Name:   name,
+		Status: StatusUp,
+	}
+	defer func() {
+		if pnc := recover(); pnc != nil {
+			result.Status = StatusDown
+			result.Error = fmt.Sprintf("发生panic: %v", pnc)
+		}
+		result.Latency = time.Since(start).String()
+	}()
+	if err := checker(ctx); err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,280 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+
+	"github.com/zjutjh/mygo/config"
+)
+
+// 访问日志可选字段 app time ts 固定记录
+const (
+	FieldAPI        = "api"
+	FieldRoute      = "route"
+	FieldMethod     = "method"
+	FieldClientIP   = "client_ip"
+	FieldQuery      = "query"
+	FieldHeader     = "header"
+	FieldLatency    = "latency"
+	FieldStatusCode = "status_code"
+	FieldRequestID  = "request_id"
+	FieldSize       = "size"
+	FieldError      = "error"
+)
+
+var accessLogFields = []string{
+	FieldAPI, FieldRoute, FieldMethod, FieldClientIP, FieldQuery, FieldHeader, FieldLatency,
+	FieldStatusCode, FieldRequestID, FieldSize, FieldError,
+}
+
+// redacted 脱敏后的值
+const redacted = "***"
+
+// noAccessLogKey 路由关闭访问日志标记
+const noAccessLogKey = "_httpserver_no_access_log_"
+
+type AccessLogConfig struct {
+	Fields        []string          `mapstructure:"fields"`         // Fields 记录的字段 app time ts 固定记录 慢请求与失败请求记录全部字段
+	RedactHeaders []string          `mapstructure:"redact_headers"` // RedactHeaders 脱敏的请求头 不区分大小写
+	RedactQuery   []string          `mapstructure:"redact_query"`   // RedactQuery 脱敏的查询参数 不区分大小写
+	Body          BodyCaptureConfig `mapstructure:"body"`
+	SkipPaths     []string          `mapstructure:"skip_paths"`     // SkipPaths 不记录访问日志的路由 与注册路由的路径一致 例: "/user/:id"
+	SlowThreshold time.Duration     `mapstructure:"slow_threshold"` // SlowThreshold 慢请求耗时阈值 为0时不区分慢请求
+	SampleRate    float64           `mapstructure:"sample_rate"`    // SampleRate 正常请求的采样比例 取值(0, 1] 慢请求与失败请求始终记录
+}
+
+// BodyCaptureConfig 请求/响应体记录配置 开启后记录到request_body与response_body字段
+type BodyCaptureConfig struct {
+	Request      bool     `mapstructure:"request"`       // Request 是否记录请求体
+	Response     bool     `mapstructure:"response"`      // Response 是否记录响应体
+	MaxSize      int      `mapstructure:"max_size"`      // MaxSize 记录的最大字节数 超出部分截断 单位 B
+	ContentTypes []string `mapstructure:"content_types"` // ContentTypes 记录的内容类型前缀 例: "application/json" "text/"
+}
+
+// validate 校验访问日志配置
+func (c AccessLogConfig) validate() error {
+	for _, f := range c.Fields {
+		if !slices.Contains(accessLogFields, f) {
+			return fmt.Errorf("访问日志字段[access_log.fields=%s]不支持 可选: %s", f, strings.Join(accessLogFields, " "))
+		}
+	}
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		return fmt.Errorf("访问日志采样比例[access_log.sample_rate=%v]必须在(0, 1]范围内", c.SampleRate)
+	}
+	if c.SlowThreshold < 0 {
+		return errors.New("慢请求耗时阈值[access_log.slow_threshold]不能为负数")
+	}
+	if (c.Body.Request || c.Body.Response) && c.Body.MaxSize <= 0 {
+		return errors.New("记录请求/响应体时最大字节数[access_log.body.max_size]必须大于0")
+	}
+	return nil
+}
+
+// NoAccessLog 关闭路由的访问日志 慢请求与失败请求仍会记录
+// 例: engine.GET("/ping", httpserver.NoAccessLog, handler)
+func NoAccessLog(ctx *gin.Context) {
+	ctx.Set(noAccessLogKey, true)
+}
+
+// bodyWriter 记录响应体前MaxSize字节的ResponseWriter
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyWriter) capture(b []byte) {
+	if remain := w.limit + 1 - w.buf.Len(); remain > 0 {
+		w.buf.Write(b[:min(len(b), remain)])
+	}
+}
+
+// accessLogger 访问日志中间件
+func accessLogger(conf AccessLogConfig, out io.Writer) gin.HandlerFunc {
+	fields := map[string]bool{}
+	for _, f := range conf.Fields {
+		fields[f] = true
+	}
+	redactHeaders := map[string]bool{}
+	for _, h := range conf.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	redactQuery := map[string]bool{}
+	for _, q := range conf.RedactQuery {
+		redactQuery[strings.ToLower(q)] = true
+	}
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		path := ctx.Request.URL.Path
+
+		// 记录请求体 读取后回填 不影响业务读取
+		var reqBody []byte
+		var reqTruncated bool
+		if conf.Body.Request && ctx.Request.Body != nil && matchContentType(conf.Body.ContentTypes, ctx.ContentType()) {
+			buf, err := io.ReadAll(io.LimitReader(ctx.Request.Body, int64(conf.Body.MaxSize)+1))
+			if err == nil {
+				ctx.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), ctx.Request.Body), Closer: ctx.Request.Body}
+				reqBody, reqTruncated = truncate(buf, conf.Body.MaxSize)
+			}
+		}
+		var bw *bodyWriter
+		if conf.Body.Response {
+			bw = &bodyWriter{ResponseWriter: ctx.Writer, limit: conf.Body.MaxSize}
+			ctx.Writer = bw
+		}
+
+		ctx.Next()
+
+		latency := time.Since(start)
+		status := ctx.Writer.Status()
+		full := status >= http.StatusBadRequest || len(ctx.Errors) > 0 ||
+			(conf.SlowThreshold > 0 && latency >= conf.SlowThreshold)
+		if !full {
+			if ctx.GetBool(noAccessLogKey) || slices.Contains(conf.SkipPaths, ctx.FullPath()) {
+				return
+			}
+			if conf.SampleRate < 1 && rand.Float64() >= conf.SampleRate {
+				return
+			}
+		}
+		has := func(f string) bool { return full || fields[f] }
+
+		if latency > time.Minute {
+			latency = latency.Truncate(time.Second)
+		}
+		data := map[string]any{
+			"app":  config.AppName(),
+			"time": start.UnixMilli(),
+			"ts":   start.Format(time.DateTime),
+		}
+		if has(FieldAPI) {
+			data[FieldAPI] = path
+		}
+		if has(FieldRoute) {
+			data[FieldRoute] = ctx.FullPath()
+		}
+		if has(FieldMethod) {
+			data[FieldMethod] = ctx.Request.Method
+		}
+		if has(FieldClientIP) {
+			data[FieldClientIP] = ctx.ClientIP()
+		}
+		if has(FieldQuery) {
+			data[FieldQuery] = redactValues(ctx.Request.URL.Query(), redactQuery)
+		}
+		if has(FieldHeader) {
+			data[FieldHeader] = redactHeader(ctx.Request.Header, redactHeaders)
+		}
+		if has(FieldLatency) {
+			data[FieldLatency] = latency.String()
+		}
+		if has(FieldStatusCode) {
+			data[FieldStatusCode] = status
+		}
+		if has(FieldRequestID) {
+			data[FieldRequestID] = requestid.Get(ctx)
+		}
+		if has(FieldSize) {
+			data[FieldSize] = ctx.Writer.Size()
+		}
+		if has(FieldError) && len(ctx.Errors) > 0 {
+			data[FieldError] = ctx.Errors.ByType(gin.ErrorTypePrivate).String()
+		}
+		if reqBody != nil {
+			data["request_body"] = string(reqBody)
+			data["request_body_truncated"] = reqTruncated
+		}
+		if bw != nil && matchContentType(conf.Body.ContentTypes, ctx.Writer.Header().Get("Content-Type")) {
+			body, truncated := truncate(bw.buf.Bytes(), conf.Body.MaxSize)
+			data["response_body"] = string(body)
+			data["response_body_truncated"] = truncated
+		}
+		if full {
+			data["full"] = true
+		}
+
+		db, _ := json.Marshal(data)
+		_, _ = fmt.Fprintf(out, "%s\n", string(db))
+	}
+}
+
+// readCloser 组合Reader与原请求体Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// truncate 截断至limit字节 返回是否发生截断
+func truncate(b []byte, limit int) ([]byte, bool) {
+	if len(b) > limit {
+		return b[:limit], true
+	}
+	return b, false
+}
+
+// matchContentType 判断内容类型是否匹配任一前缀 未配置前缀时全部匹配
+func matchContentType(prefixes []string, contentType string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(mediaType, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeader 复制请求头并脱敏
+func redactHeader(h http.Header, keys map[string]bool) http.Header {
+	res := make(http.Header, len(h))
+	for k, v := range h {
+		if keys[http.CanonicalHeaderKey(k)] {
+			res[k] = []string{redacted}
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
+
+// redactValues 复制查询参数并脱敏
+func redactValues(values url.Values, keys map[string]bool) url.Values {
+	res := make(url.Values, len(values))
+	for k, v := range values {
+		if keys[strings.ToLower(k)] {
+			res[k] = []string{redacted}
+			continue
+		}
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/reply"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nlog"
+)
+
+// requestMetrics 主服务请求计数 按HTTP状态码统计 通过管理端 /metrics 查看
+var requestMetrics = expvar.NewMap("http_server_requests")
+
+// LogLevel 日志实例记录等级
+type LogLevel struct {
+	Scope string `json:"scope"`
+	Level string `json:"level"`
+}
+
+// metricsMiddleware 统计主服务请求数
+func metricsMiddleware(ctx *gin.Context) {
+	ctx.Next()
+	requestMetrics.Add("total", 1)
+	requestMetrics.Add(strconv.Itoa(ctx.Writer.Status()), 1)
+}
+
+// newAdminServer 创建管理端HTTP Server 与主服务使用不同监听地址
+// GET /debug/pprof/*          pprof
+// GET {health.liveness_path}  存活检查
+// GET {health.readiness_path} 就绪检查
+// GET /metrics                expvar指标 含运行时内存统计与主服务请求计数
+// GET /routes                 主服务路由表
+// GET /log/level              全部日志实例记录等级
+// PUT /log/level/:scope       修改日志实例记录等级 ?level=debug 配置变更时以配置为准
+func newAdminServer(conf Config, public *gin.Engine) *http.Server {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+
+	pprof.Register(engine)
+	engine.GET(conf.Health.LivenessPath, healthHandler(conf.Health, false))
+	engine.GET(conf.Health.ReadinessPath, healthHandler(conf.Health, true))
+	engine.GET("/metrics", gin.WrapH(expvar.Handler()))
+	engine.GET("/routes", func(ctx *gin.Context) {
+		routes, err := RouteTable(public)
+		if err != nil {
+			reply.Fail(ctx, kit.CodeUnknownError)
+			return
+		}
+		reply.Success(ctx, routes)
+	})
+	engine.GET("/log/level", func(ctx *gin.Context) {
+		levels := []LogLevel{}
+		for _, r := range config.Records() {
+			if r.Component == "nlog" && nlog.Exist(r.Scope) {
+				levels = append(levels, LogLevel{Scope: r.Scope, Level: nlog.Pick(r.Scope).GetLevel().String()})
+			}
+		}
+		reply.Success(ctx, levels)
+	})
+	engine.PUT("/log/level/:scope", func(ctx *gin.Context) {
+		scope := ctx.Param("scope")
+		if !nlog.Exist(scope) {
+			reply.Fail(ctx, kit.CodeDataNotFound)
+			return
+		}
+		level, err := logrus.ParseLevel(ctx.Query("level"))
+		if err != nil {
+			reply.Fail(ctx, kit.CodeParameterInvalid)
+			return
+		}
+		nlog.Pick(scope).SetLevel(level)
+		reply.Success(ctx, LogLevel{Scope: scope, Level: level.String()})
+	})
+
+	return &http.Server{
+		Addr:              conf.AdminAddr,
+		Handler:           engine,
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
+	}
+}
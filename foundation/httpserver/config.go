@@ -1,14 +1,67 @@
 package httpserver
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zjutjh/mygo/kit"
+)
 
 var DefaultConfig = Config{
-	Addr: ":8888",
+	Addr:      ":8888",
+	AdminAddr: "",
+
+	ReadTimeout:       0,
+	ReadHeaderTimeout: 10 * time.Second,
+	WriteTimeout:      0,
+	IdleTimeout:       2 * time.Minute,
+	MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
 
 	ShutdownWaitTimeout: 10 * time.Second,
+	ShutdownDelay:       5 * time.Second,
 
 	Pprof: false,
 
+	Upgrade: UpgradeConfig{
+		Enable:       false,
+		Signal:       "SIGUSR2",
+		ReadyTimeout: 30 * time.Second,
+	},
+
+	TLS: TLSConfig{
+		Enable:         false,
+		MinVersion:     "1.2",
+		ClientAuth:     ClientAuthRequire,
+		ReloadInterval: 10 * time.Second,
+	},
+
+	Health: HealthConfig{
+		Enable:        false,
+		LivenessPath:  "/healthz",
+		ReadinessPath: "/readyz",
+		Timeout:       3 * time.Second,
+	},
+
+	AccessLog: AccessLogConfig{
+		Fields: []string{
+			FieldAPI, FieldMethod, FieldClientIP, FieldQuery, FieldHeader,
+			FieldLatency, FieldStatusCode, FieldRequestID, FieldError,
+		},
+		RedactHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		RedactQuery:   []string{"token", "access_token", "password", "secret"},
+		Body: BodyCaptureConfig{
+			Request:      false,
+			Response:     false,
+			MaxSize:      4 << 10,
+			ContentTypes: []string{"application/json", "application/x-www-form-urlencoded", "text/"},
+		},
+		SkipPaths:     nil,
+		SlowThreshold: time.Second,
+		SampleRate:    1,
+	},
+
 	Log: LogConfig{
 		AccessFilename: "./logs/access.log",
 		ErrorFilename:  "./logs/error.log",
@@ -20,22 +73,99 @@ var DefaultConfig = Config{
 	},
 
 	Gin: GinConfig{
-		UseH2C: false,
+		UseH2C:                 false,
+		TrustedProxies:         nil,
+		RemoteIPHeaders:        []string{"X-Forwarded-For", "X-Real-IP"},
+		RedirectTrailingSlash:  true,
+		HandleMethodNotAllowed: false,
+		MaxMultipartMemory:     32 << 20,
+		NotFound: NoRouteReplyConfig{
+			Enable:  false,
+			Status:  http.StatusNotFound,
+			Code:    kit.CodeDataNotFound.Code,
+			Message: "接口不存在",
+		},
+		MethodNotAllowed: NoRouteReplyConfig{
+			Enable:  false,
+			Status:  http.StatusMethodNotAllowed,
+			Code:    kit.CodeParameterInvalid.Code,
+			Message: "请求方法不允许",
+		},
 	},
 }
 
 type Config struct {
-	Addr string `mapstructure:"addr"`
+	Addr      string `mapstructure:"addr"`
+	AdminAddr string `mapstructure:"admin_addr"` // AdminAddr 管理端监听地址 配置后pprof、健康检查、指标、路由表与日志等级接口仅挂载在管理端 例: "127.0.0.1:8889"
+
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`        // ReadTimeout 读取整个请求(含请求体)的超时时间 为0时不限制
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"` // ReadHeaderTimeout 读取请求头的超时时间 为0时取ReadTimeout
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`       // WriteTimeout 写响应的超时时间 为0时不限制 流式响应需谨慎设置
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`        // IdleTimeout keep-alive连接空闲超时时间 为0时取ReadTimeout
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes"`    // MaxHeaderBytes 请求头最大字节数
 
 	ShutdownWaitTimeout time.Duration `mapstructure:"shutdown_wait_timeout"`
+	ShutdownDelay       time.Duration `mapstructure:"shutdown_delay"` // ShutdownDelay 就绪检查转为失败后 等待探针与负载均衡摘除流量的时间 为0时立即关闭 未暴露就绪检查接口时不等待
+
+	Pprof bool `mapstructure:"pprof"` // Pprof 是否在主服务挂载pprof 配置AdminAddr时不生效 pprof固定挂载在管理端
+
+	Upgrade UpgradeConfig `mapstructure:"upgrade"`
+
+	TLS TLSConfig `mapstructure:"tls"`
+
+	Health HealthConfig `mapstructure:"health"`
 
-	Pprof bool `mapstructure:"pprof"`
+	AccessLog AccessLogConfig `mapstructure:"access_log"`
 
 	Log LogConfig `mapstructure:"log"`
 
 	Gin GinConfig `mapstructure:"gin"`
 }
 
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.ReadTimeout < 0 || c.ReadHeaderTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		return errors.New("读写超时时间[read_timeout/read_header_timeout/write_timeout/idle_timeout]不能为负数")
+	}
+	if c.MaxHeaderBytes < 0 {
+		return errors.New("请求头最大字节数[max_header_bytes]不能为负数")
+	}
+	if err := c.AccessLog.validate(); err != nil {
+		return err
+	}
+	if err := c.Upgrade.validate(); err != nil {
+		return err
+	}
+	if err := c.Gin.validate(); err != nil {
+		return err
+	}
+	return c.TLS.validate()
+}
+
+// UpgradeConfig 平滑升级配置 仅支持类Unix系统
+// 收到升级信号时以相同参数启动新的可执行文件并传递监听器 新进程就绪后当前进程停止接收请求并在处理完成后退出
+type UpgradeConfig struct {
+	Enable       bool          `mapstructure:"enable"`        // Enable 是否启用平滑升级
+	Signal       string        `mapstructure:"signal"`        // Signal 触发升级的信号 可选: SIGHUP SIGUSR1 SIGUSR2
+	ReadyTimeout time.Duration `mapstructure:"ready_timeout"` // ReadyTimeout 等待新进程就绪的超时时间 超时后终止新进程并继续提供服务
+}
+
+// validate 校验平滑升级配置
+func (c UpgradeConfig) validate() error {
+	if !c.Enable {
+		return nil
+	}
+	switch c.Signal {
+	case "SIGHUP", "SIGUSR1", "SIGUSR2":
+	default:
+		return fmt.Errorf("平滑升级信号[upgrade.signal=%s]不支持", c.Signal)
+	}
+	if c.ReadyTimeout <= 0 {
+		return errors.New("等待新进程就绪超时时间[upgrade.ready_timeout]必须大于0")
+	}
+	return nil
+}
+
 type LogConfig struct {
 	AccessFilename string `mapstructure:"access_filename"` // AccessFilename 日志文件路径
 	ErrorFilename  string `mapstructure:"error_filename"`  // ErrorFilename 日志文件路径
@@ -46,8 +176,51 @@ type LogConfig struct {
 	Compress       bool   `mapstructure:"compress"`        // Compress 日志切割后是否对归档文件进行压缩
 }
 
-// Gin配置 有需要时再补充
+type HealthConfig struct {
+	Enable        bool          `mapstructure:"enable"`         // Enable 是否在主服务挂载健康检查接口 开启时需避免与业务路由冲突 配置AdminAddr时不生效 固定挂载在管理端
+	LivenessPath  string        `mapstructure:"liveness_path"`  // LivenessPath 存活检查接口路径
+	ReadinessPath string        `mapstructure:"readiness_path"` // ReadinessPath 就绪检查接口路径
+	Timeout       time.Duration `mapstructure:"timeout"`        // Timeout 单次检查超时时间
+}
+
 type GinConfig struct {
-	UseH2C bool `mapstructure:"use_h2c"`
-	// ......
+	UseH2C                 bool     `mapstructure:"use_h2c"`
+	TrustedProxies         []string `mapstructure:"trusted_proxies"`           // TrustedProxies 可信代理IP或CIDR 仅信任来自这些代理的RemoteIPHeaders 为空时信任全部代理
+	RemoteIPHeaders        []string `mapstructure:"remote_ip_headers"`         // RemoteIPHeaders 获取客户端真实IP的请求头 按顺序取第一个有效值
+	RedirectTrailingSlash  bool     `mapstructure:"redirect_trailing_slash"`   // RedirectTrailingSlash 路由仅在末尾斜杠不同时是否重定向
+	HandleMethodNotAllowed bool     `mapstructure:"handle_method_not_allowed"` // HandleMethodNotAllowed 路径存在但请求方法不匹配时是否响应405 否则响应404
+	MaxMultipartMemory     int64    `mapstructure:"max_multipart_memory"`      // MaxMultipartMemory 解析multipart表单时使用的最大内存 超出部分写入临时文件 单位 B
+
+	NotFound         NoRouteReplyConfig `mapstructure:"not_found"`          // NotFound 路由不存在时的响应
+	MethodNotAllowed NoRouteReplyConfig `mapstructure:"method_not_allowed"` // MethodNotAllowed 请求方法不允许时的响应 需开启HandleMethodNotAllowed
+}
+
+// validate 校验Gin配置
+func (c GinConfig) validate() error {
+	if c.MaxMultipartMemory < 0 {
+		return errors.New("multipart表单最大内存[gin.max_multipart_memory]不能为负数")
+	}
+	if err := c.NotFound.validate(); err != nil {
+		return fmt.Errorf("路由不存在响应[gin.not_found]配置错误: %w", err)
+	}
+	if err := c.MethodNotAllowed.validate(); err != nil {
+		return fmt.Errorf("请求方法不允许响应[gin.method_not_allowed]配置错误: %w", err)
+	}
+	return nil
+}
+
+// NoRouteReplyConfig 未匹配路由时的标准JSON响应
+type NoRouteReplyConfig struct {
+	Enable  bool   `mapstructure:"enable"`  // Enable 是否以标准JSON响应 否则使用gin默认的纯文本响应
+	Status  int    `mapstructure:"status"`  // Status HTTP状态码
+	Code    int64  `mapstructure:"code"`    // Code 业务状态码
+	Message string `mapstructure:"message"` // Message 业务状态信息
+}
+
+// validate 校验响应配置
+func (c NoRouteReplyConfig) validate() error {
+	if c.Enable && (c.Status < 100 || c.Status > 599) {
+		return fmt.Errorf("HTTP状态码[%d]错误", c.Status)
+	}
+	return nil
 }
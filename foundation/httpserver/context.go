@@ -0,0 +1,22 @@
+package httpserver
+
+import (
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// contextMiddleware 将请求ID与链路信息写入请求上下文 供日志、数据库、缓存及出站请求读取
+// 上游携带traceparent时沿用其链路ID 否则生成新的链路ID
+func contextMiddleware(ctx *gin.Context) {
+	meta := kit.NewMeta()
+	if rid := requestid.Get(ctx); rid != "" {
+		meta.RequestID = rid
+	}
+	if traceID, _, ok := kit.ParseTraceParent(ctx.GetHeader(kit.HeaderTraceParent)); ok {
+		meta.TraceID = traceID
+	}
+	ctx.Request = ctx.Request.WithContext(kit.WithMeta(ctx.Request.Context(), meta))
+	ctx.Set(kit.MetaKey, meta)
+}
@@ -0,0 +1,31 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zjutjh/mygo/foundation/health"
+)
+
+// healthHandler 健康检查接口 全部组件健康时响应200 否则响应503
+func healthHandler(conf HealthConfig, readiness bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c, cancel := context.WithTimeout(ctx.Request.Context(), conf.Timeout)
+		defer cancel()
+
+		var report health.Report
+		if readiness {
+			report = health.Ready(c)
+		} else {
+			report = health.Live(c)
+		}
+
+		code := http.StatusOK
+		if report.Status != health.StatusUp {
+			code = http.StatusServiceUnavailable
+		}
+		ctx.JSON(code, report)
+	}
+}
@@ -0,0 +1,67 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// 监听器名称 用于平滑升级时传递监听器及systemd socket激活时匹配FileDescriptorName
+const (
+	listenerHTTP  = "http"
+	listenerAdmin = "admin"
+)
+
+// namedListener 带名称的监听器
+type namedListener struct {
+	name     string
+	listener net.Listener
+}
+
+// parseAddr 解析监听地址 "unix:"前缀为Unix Socket 其余为TCP地址
+func parseAddr(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", addr
+}
+
+// listen 创建监听器 优先使用平滑升级时父进程传递或systemd socket激活的监听器
+func listen(name, addr string) (net.Listener, error) {
+	l, ok, err := inheritedListener(name)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return l, nil
+	}
+
+	network, address := parseAddr(addr)
+	if network == "unix" {
+		// 清理上次运行遗留的Socket文件
+		if info, err := os.Stat(address); err == nil && info.Mode()&fs.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+	}
+	l, err = net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("监听地址[%s]错误: %w", addr, err)
+	}
+	if ul, ok := l.(*net.UnixListener); ok {
+		// 关闭时不删除Socket文件 平滑升级后新进程仍在使用
+		ul.SetUnlinkOnClose(false)
+	}
+	return l, nil
+}
+
+// closeListeners 关闭监听器 忽略已关闭错误
+func closeListeners(listeners []namedListener) {
+	for _, nl := range listeners {
+		if err := nl.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			fmt.Fprintf(os.Stdout, "关闭监听器[%s]错误: %s\n", nl.name, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/swagger"
+)
+
+// Route 路由信息
+type Route struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+}
+
+var (
+	routeMu         sync.RWMutex
+	currentRegister func(*gin.Engine)
+)
+
+// setRouteRegister 记录应用注册的路由注册器
+func setRouteRegister(routeRegister func(*gin.Engine)) {
+	routeMu.Lock()
+	defer routeMu.Unlock()
+	currentRegister = routeRegister
+}
+
+// Engine 以config.yaml[http_server]创建gin引擎并注册应用路由 不启动监听 用于路由查看等场景
+func Engine() (*gin.Engine, error) {
+	routeMu.RLock()
+	routeRegister := currentRegister
+	routeMu.RUnlock()
+	if routeRegister == nil {
+		return nil, fmt.Errorf("%w: 未注册HTTP Server路由", kit.ErrNotFound)
+	}
+
+	conf, err := getConf()
+	if err != nil {
+		return nil, err
+	}
+	return newEngine(conf, routeRegister)
+}
+
+// RouteTable 获取gin引擎的路由表 包含各路由的中间件链 按路径与方法排序
+func RouteTable(engine *gin.Engine) ([]Route, error) {
+	middlewares, err := swagger.RouteMiddlewares(engine)
+	if err != nil {
+		return nil, fmt.Errorf("获取路由中间件错误: %w", err)
+	}
+
+	routes := engine.Routes()
+	res := make([]Route, 0, len(routes))
+	for _, r := range routes {
+		mws := middlewares[r.Method][r.Path]
+		if mws == nil {
+			mws = []string{}
+		}
+		res = append(res, Route{
+			Method:      r.Method,
+			Path:        r.Path,
+			Handler:     r.Handler,
+			Middlewares: mws,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Method < res[j].Method
+	})
+	return res, nil
+}
@@ -2,17 +2,20 @@ package httpserver
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
+	"gopkg.in/natefinch/lumberjack.v2"
+
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
 	"github.com/spf13/cobra"
 	"github.com/zjutjh/mygo/config"
 	"github.com/zjutjh/mygo/feishu"
@@ -23,45 +26,151 @@ import (
 
 // CommandRegister 启动HTTP Server命令注册
 func CommandRegister(routeRegister func(engine *gin.Engine)) func(cmd *cobra.Command, args []string) error {
+	setRouteRegister(routeRegister)
 	return func(cmd *cobra.Command, args []string) error {
-		StartHTTPServer(routeRegister)
-		return nil
+		return StartHTTPServerE(routeRegister)
 	}
 }
 
-// StartHTTPServer 启动HTTP Server
+// StartHTTPServer 启动HTTP Server 发生错误时退出进程
 func StartHTTPServer(routeRegister func(*gin.Engine)) {
-	// 获取配置
-	conf := DefaultConfig
-	config.Pick().UnmarshalKey("http_server", &conf)
+	if err := StartHTTPServerE(routeRegister); err != nil {
+		fmt.Fprintln(os.Stdout, "HTTP Server运行错误:", err)
+		os.Exit(1)
+	}
+}
 
-	// 初始化gin引擎
-	engine, err := initGinEngine(conf)
+// StartHTTPServerE 启动HTTP Server 返回运行过程中发生的错误
+func StartHTTPServerE(routeRegister func(*gin.Engine)) error {
+	if err := kernel.Serve(NewRunnable(routeRegister)); err != nil {
+		return fmt.Errorf("%w: %w", kit.ErrServerRun, err)
+	}
+	return nil
+}
+
+// NewRunnable 创建可被内核托管的HTTP Server组件
+func NewRunnable(routeRegister func(*gin.Engine)) kernel.Runnable {
+	setRouteRegister(routeRegister)
+	return kernel.NewRunnable("http_server", func(ctx context.Context) error {
+		return serve(ctx, routeRegister)
+	})
+}
+
+// serve 运行HTTP Server 直至ctx结束后优雅关闭
+func serve(ctx context.Context, routeRegister func(*gin.Engine)) error {
+	// 获取配置
+	conf, err := getConf()
 	if err != nil {
-		fmt.Fprintln(os.Stdout, "初始化Gin Engine失败:", err)
-		os.Exit(1)
+		return err
 	}
 
-	// 注册路由
-	routeRegister(engine)
+	// 初始化gin引擎并注册路由
+	engine, err := newEngine(conf, routeRegister)
+	if err != nil {
+		return err
+	}
 
 	// 初始化http server
 	server := initHTTPServer(engine, conf)
+	if conf.TLS.Enable {
+		if server.TLSConfig, err = newTLSConfig(ctx, conf.TLS); err != nil {
+			return fmt.Errorf("初始化TLS配置失败: %w", err)
+		}
+	}
 
-	// 启动http server
-	go listenHTTPServer(server)
-
-	// 监听等待关闭服务
-	kernel.ListenStop(func() error {
-		ctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownWaitTimeout)
-		defer cancel()
-		if err := server.Shutdown(ctx); err != nil {
-			return fmt.Errorf("HTTP Server等待优雅处理超时, 错误: %w", err)
-		} else {
-			fmt.Fprintln(os.Stdout, "HTTP Server关闭完成")
+	// 创建监听器
+	ln, err := listen(listenerHTTP, conf.Addr)
+	if err != nil {
+		return fmt.Errorf("启动HTTP Server失败: %w", err)
+	}
+	listeners := []namedListener{{name: listenerHTTP, listener: ln}}
+	var admin *http.Server
+	if conf.AdminAddr != "" {
+		aln, err := listen(listenerAdmin, conf.AdminAddr)
+		if err != nil {
+			closeListeners(listeners)
+			return fmt.Errorf("启动HTTP Server管理端失败: %w", err)
+		}
+		admin = newAdminServer(conf, engine)
+		listeners = append(listeners, namedListener{name: listenerAdmin, listener: aln})
+	}
+
+	// 启动http server 管理端随主服务一同关闭
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- serveHTTPServer(server, ln)
+	}()
+	if admin != nil {
+		go func() {
+			if err := serveHTTPServer(admin, listeners[1].listener); err != nil {
+				errCh <- fmt.Errorf("管理端: %w", err)
+			}
+		}()
+	}
+
+	// 通知平滑升级的父进程已就绪 并监听平滑升级信号
+	notifyReady()
+	go watchUpgrade(ctx, conf.Upgrade, listeners)
+
+	// 等待关闭服务
+	select {
+	case err := <-errCh:
+		_ = server.Close()
+		if admin != nil {
+			_ = admin.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("启动HTTP Server失败: %w", err)
 		}
 		return nil
-	})
+	case <-ctx.Done():
+	}
+	if conf.ShutdownDelay > 0 && (conf.Health.Enable || admin != nil) {
+		time.Sleep(conf.ShutdownDelay)
+	}
+	sctx, cancel := context.WithTimeout(context.Background(), conf.ShutdownWaitTimeout)
+	defer cancel()
+	if err := server.Shutdown(sctx); err != nil {
+		return fmt.Errorf("HTTP Server等待优雅处理超时, 错误: %w", err)
+	}
+	if admin != nil {
+		if err := admin.Shutdown(sctx); err != nil {
+			return fmt.Errorf("HTTP Server管理端等待优雅处理超时, 错误: %w", err)
+		}
+	}
+	fmt.Fprintln(os.Stdout, "HTTP Server关闭完成")
+	return nil
+}
+
+func init() {
+	config.TrackFunc("http_server", "http_server", func() (any, error) { return getConf() })
+}
+
+// getConf 获取config.yaml[http_server]配置
+func getConf() (Config, error) {
+	// 深拷贝默认配置 避免解析时写入默认配置中切片的底层数组
+	conf := Config{}
+	if err := copier.CopyWithOption(&conf, &DefaultConfig, copier.Option{DeepCopy: true}); err != nil {
+		return conf, err
+	}
+	if err := config.UnmarshalKeyStrict(config.Pick(), "http_server", &conf); err != nil {
+		return conf, fmt.Errorf("%w: 解析config.yaml[http_server]错误: %w", kit.ErrDataUnmarshal, err)
+	}
+	if err := conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[http_server]错误: %w", kit.ErrConfigInvalid, err)
+	}
+	config.Track("http_server", "http_server", conf)
+	return conf, nil
+}
+
+// newEngine 初始化gin引擎并注册路由
+func newEngine(conf Config, routeRegister func(*gin.Engine)) (*gin.Engine, error) {
+	engine, err := initGinEngine(conf)
+	if err != nil {
+		return nil, fmt.Errorf("初始化Gin Engine失败: %w", err)
+	}
+	routeRegister(engine)
+	return engine, nil
 }
 
 func initGinEngine(conf Config) (*gin.Engine, error) {
@@ -69,88 +178,102 @@ func initGinEngine(conf Config) (*gin.Engine, error) {
 	if err != nil {
 		return nil, err
 	}
+	for name, w := range map[string]io.Writer{"access": aw, "error": ew} {
+		if lw, ok := w.(*lumberjack.Logger); ok {
+			kernel.OnStop(fmt.Sprintf("httpserver[%s_log]", name), func(ctx context.Context) error {
+				return lw.Close()
+			})
+		}
+	}
 
 	// 创建gin引擎实例
 	engine := gin.New()
 
-	// 设置gin参数 有需要时再补充
+	// 设置gin参数
 	engine.UseH2C = conf.Gin.UseH2C
-	// engine.RedirectTrailingSlash = conf.Gin.RedirectTrailingSlash
-	// ......
+	engine.RedirectTrailingSlash = conf.Gin.RedirectTrailingSlash
+	engine.HandleMethodNotAllowed = conf.Gin.HandleMethodNotAllowed
+	engine.MaxMultipartMemory = conf.Gin.MaxMultipartMemory
+	engine.RemoteIPHeaders = conf.Gin.RemoteIPHeaders
+	if len(conf.Gin.TrustedProxies) > 0 {
+		if err := engine.SetTrustedProxies(conf.Gin.TrustedProxies); err != nil {
+			return nil, fmt.Errorf("设置可信代理[gin.trusted_proxies]错误: %w", err)
+		}
+	}
 
 	// 创建gin全局日志记录
-	logger := gin.LoggerWithConfig(gin.LoggerConfig{
-		Formatter: accessLoggerFormatter(),
-		Output:    aw,
-	})
+	logger := accessLogger(conf.AccessLog, aw)
 	// 设置gin崩溃恢复中间件
 	recovery := gin.RecoveryWithWriter(ew, recoveryHandler)
 
 	// 设置gin全局中间件
-	engine.Use(requestid.New(), logger, recovery)
+	engine.Use(requestid.New(), contextMiddleware, logger, recovery)
+	if conf.AdminAddr != "" {
+		engine.Use(metricsMiddleware)
+	}
+
+	// 设置未匹配路由响应
+	if conf.Gin.NotFound.Enable {
+		engine.NoRoute(noRouteHandler(conf.Gin.NotFound))
+	}
+	if conf.Gin.MethodNotAllowed.Enable {
+		engine.NoMethod(noRouteHandler(conf.Gin.MethodNotAllowed))
+	}
 
-	// 设置gin pprof
-	if conf.Pprof {
+	// 设置健康检查接口 配置管理端时仅挂载在管理端
+	if conf.Health.Enable && conf.AdminAddr == "" {
+		engine.GET(conf.Health.LivenessPath, healthHandler(conf.Health, false))
+		engine.GET(conf.Health.ReadinessPath, healthHandler(conf.Health, true))
+	}
+
+	// 设置gin pprof 配置管理端时仅挂载在管理端
+	if conf.Pprof && conf.AdminAddr == "" {
 		pprof.Register(engine, fmt.Sprintf("%s%s", config.AppName(), pprof.DefaultPrefix))
 	}
 
 	return engine, nil
 }
 
-func accessLoggerFormatter() gin.LogFormatter {
-	return func(param gin.LogFormatterParams) string {
-		if param.Latency > time.Minute {
-			param.Latency = param.Latency.Truncate(time.Second)
-		}
-
-		data := map[string]any{
-			"app":         config.AppName(),
-			"time":        param.TimeStamp.UnixMilli(),
-			"ts":          param.TimeStamp.Format(time.DateTime),
-			"api":         param.Path,
-			"method":      param.Method,
-			"client_ip":   param.ClientIP,
-			"query":       param.Request.URL.Query(),
-			"header":      param.Request.Header,
-			"latency":     param.Latency.String(),
-			"status_code": param.StatusCode,
-		}
-		if param.ErrorMessage != "" {
-			data["error"] = param.ErrorMessage
-		}
-		db, _ := json.Marshal(data)
-		return fmt.Sprintf("%s\n", string(db))
+// noRouteHandler 未匹配路由时以标准JSON响应
+func noRouteHandler(conf NoRouteReplyConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.AbortWithStatusJSON(conf.Status, reply.Response{
+			Code:    conf.Code,
+			Message: conf.Message,
+			Data:    nil,
+		})
 	}
 }
 
 func recoveryHandler(ctx *gin.Context, err any) {
 	reply.Fail(ctx, kit.CodeUnknownError)
 	// 发送报警
-	if !feishu.Exist() {
-		return
-	}
-	go func() {
-		defer func() {
-			if err := recover(); err != nil {
-				log.Println("请求飞书Bot发送报警发生了Panic:", err)
-			}
-		}()
-		title := fmt.Sprintf("[%s]HTTP Server Panic!!!", config.AppName())
-		message := fmt.Sprintf("请注意: HTTP Server发生了Panic!!!\nPanic: %#v", err)
-		feishu.Pick().Send(title, message)
-	}()
+	feishu.Alert(fmt.Sprintf("[%s]HTTP Server Panic!!!", config.AppName()),
+		fmt.Sprintf("请注意: HTTP Server发生了Panic!!!\nPanic: %#v", err))
 }
 
 func initHTTPServer(e *gin.Engine, conf Config) *http.Server {
 	return &http.Server{
-		Addr:    conf.Addr,
-		Handler: e.Handler(),
+		Addr:              conf.Addr,
+		Handler:           e.Handler(),
+		ReadTimeout:       conf.ReadTimeout,
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
 	}
 }
 
-func listenHTTPServer(s *http.Server) {
-	err := s.ListenAndServe()
-	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		fmt.Fprintln(os.Stdout, "启动HTTP Server失败:", err)
+func serveHTTPServer(s *http.Server, l net.Listener) error {
+	var err error
+	if s.TLSConfig != nil {
+		// 证书由TLSConfig提供
+		err = s.ServeTLS(l, "", "")
+	} else {
+		err = s.Serve(l)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
 	}
+	return err
 }
@@ -0,0 +1,211 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// 客户端证书校验方式
+const (
+	ClientAuthRequire       = "require"         // 必须提供并通过校验
+	ClientAuthVerifyIfGiven = "verify_if_given" // 提供时校验
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type TLSConfig struct {
+	Enable         bool          `mapstructure:"enable"`          // Enable 是否启用HTTPS
+	CertFile       string        `mapstructure:"cert_file"`       // CertFile 证书文件路径 PEM格式 可包含证书链
+	KeyFile        string        `mapstructure:"key_file"`        // KeyFile 私钥文件路径 PEM格式
+	MinVersion     string        `mapstructure:"min_version"`     // MinVersion 最低TLS版本 可选: 1.0 1.1 1.2 1.3
+	CipherSuites   []string      `mapstructure:"cipher_suites"`   // CipherSuites TLS1.2及以下版本可用的加密套件名 例: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 为空时使用Go默认值
+	ClientCAFile   string        `mapstructure:"client_ca_file"`  // ClientCAFile 校验客户端证书的CA文件路径 配置后启用双向TLS
+	ClientAuth     string        `mapstructure:"client_auth"`     // ClientAuth 客户端证书校验方式 可选: require verify_if_given
+	ReloadInterval time.Duration `mapstructure:"reload_interval"` // ReloadInterval 检查证书文件变更的间隔 变更后重新加载且不影响已建立的连接 为0时不检查
+}
+
+// validate 校验TLS配置
+func (c TLSConfig) validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("启用TLS时必须配置证书文件[tls.cert_file]与私钥文件[tls.key_file]")
+	}
+	if _, ok := tlsVersions[c.MinVersion]; !ok {
+		return fmt.Errorf("TLS最低版本[tls.min_version=%s]不支持", c.MinVersion)
+	}
+	if _, err := cipherSuites(c.CipherSuites); err != nil {
+		return err
+	}
+	switch c.ClientAuth {
+	case ClientAuthRequire, ClientAuthVerifyIfGiven:
+	default:
+		return fmt.Errorf("客户端证书校验方式[tls.client_auth=%s]不支持", c.ClientAuth)
+	}
+	if c.ReloadInterval < 0 {
+		return errors.New("证书检查间隔[tls.reload_interval]不能为负数")
+	}
+	return nil
+}
+
+// cipherSuites 将加密套件名转换为套件ID 仅支持Go认为安全的套件
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	supported := map[string]uint16{}
+	for _, s := range tls.CipherSuites() {
+		supported[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("TLS加密套件[%s]不支持", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// newTLSConfig 创建HTTPS使用的tls.Config 配置检查间隔时在ctx结束前定期检查证书文件变更
+func newTLSConfig(ctx context.Context, conf TLSConfig) (*tls.Config, error) {
+	suites, err := cipherSuites(conf.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	r := &certReloader{conf: conf}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	tc := &tls.Config{
+		MinVersion:     tlsVersions[conf.MinVersion],
+		CipherSuites:   suites,
+		GetCertificate: r.certificate,
+	}
+	if conf.ClientCAFile != "" {
+		tc.ClientAuth = tls.RequireAndVerifyClientCert
+		if conf.ClientAuth == ClientAuthVerifyIfGiven {
+			tc.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		tc.ClientCAs = r.clientCAs()
+		// 每次握手取最新加载的客户端CA 握手配置不会再由http.Server补充ALPN 需显式声明
+		tc.NextProtos = []string{"h2", "http/1.1"}
+		base := tc.Clone()
+		tc.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := base.Clone()
+			c.ClientCAs = r.clientCAs()
+			return c, nil
+		}
+	}
+
+	if conf.ReloadInterval > 0 {
+		go r.watch(ctx)
+	}
+	return tc, nil
+}
+
+// certReloader 可热加载的证书与客户端CA
+type certReloader struct {
+	conf TLSConfig
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+// load 加载证书与客户端CA 并记录文件修改时间
+func (r *certReloader) load() error {
+	modTime := map[string]time.Time{}
+	for _, file := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("读取TLS文件[%s]错误: %w", file, err)
+		}
+		modTime[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书[%s]错误: %w", r.conf.CertFile, err)
+	}
+	var pool *x509.CertPool
+	if r.conf.ClientCAFile != "" {
+		data, err := os.ReadFile(r.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("读取客户端CA文件[%s]错误: %w", r.conf.ClientCAFile, err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("客户端CA文件[%s]中没有有效的PEM证书", r.conf.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTime = &cert, pool, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// changed 判断证书相关文件是否发生变更
+func (r *certReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for file, t := range r.modTime {
+		info, err := os.Stat(file)
+		if err == nil && !info.ModTime().Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// watch 定期检查证书文件变更并重新加载 加载失败时继续使用原证书
+func (r *certReloader) watch(ctx context.Context) {
+	ticker := time.NewTicker(r.conf.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.load(); err != nil {
+				fmt.Fprintln(os.Stdout, "HTTP Server重新加载TLS证书失败, 继续使用原证书:", err)
+				continue
+			}
+			fmt.Fprintln(os.Stdout, "HTTP Server已重新加载TLS证书")
+		}
+	}
+}
+
+func (r *certReloader) certificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) clientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
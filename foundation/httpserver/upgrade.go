@@ -0,0 +1,213 @@
+//go:build !windows
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zjutjh/mygo/foundation/kernel"
+)
+
+// 平滑升级时父进程传递给新进程的环境变量
+const (
+	envListeners = "MYGO_LISTENERS" // 传递的监听器名称 以逗号分隔 依次对应fd 3, 4, ...
+	envReadyFD   = "MYGO_READY_FD"  // 新进程就绪后写入的管道fd
+)
+
+// listenFDsStart systemd socket激活与平滑升级传递的首个fd
+const listenFDsStart = 3
+
+var upgradeSignals = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+var (
+	inheritOnce sync.Once
+	inherited   map[string]net.Listener
+	inheritErr  error
+)
+
+// inheritedListener 获取平滑升级时父进程传递或systemd socket激活的指定名称监听器
+func inheritedListener(name string) (net.Listener, bool, error) {
+	inheritOnce.Do(func() {
+		inherited, inheritErr = loadInherited()
+	})
+	if inheritErr != nil {
+		return nil, false, inheritErr
+	}
+	l, ok := inherited[name]
+	if ok {
+		delete(inherited, name)
+	}
+	return l, ok, nil
+}
+
+// loadInherited 解析继承的监听器 读取后清除相关环境变量 避免影响子进程
+func loadInherited() (map[string]net.Listener, error) {
+	var names []string
+	switch {
+	case os.Getenv(envListeners) != "":
+		// 平滑升级
+		names = strings.Split(os.Getenv(envListeners), ",")
+	case os.Getenv("LISTEN_PID") == strconv.Itoa(os.Getpid()):
+		// systemd socket激活 按FileDescriptorName匹配 未命名时依次为http与admin
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil {
+			return nil, fmt.Errorf("解析环境变量LISTEN_FDS错误: %w", err)
+		}
+		names = strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+		if len(names) != n {
+			names = []string{listenerHTTP, listenerAdmin}[:min(n, 2)]
+		}
+	}
+	for _, key := range []string{envListeners, "LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+		_ = os.Unsetenv(key)
+	}
+
+	res := make(map[string]net.Listener, len(names))
+	for i, name := range names {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("使用继承的监听器[%s] fd[%d]错误: %w", name, fd, err)
+		}
+		res[name] = l
+	}
+	return res, nil
+}
+
+// notifyReady 平滑升级启动的新进程完成监听后通知父进程 非平滑升级启动时不做处理
+func notifyReady() {
+	fd, err := strconv.Atoi(os.Getenv(envReadyFD))
+	_ = os.Unsetenv(envReadyFD)
+	if err != nil {
+		return
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	_, _ = f.Write([]byte{1})
+	_ = f.Close()
+}
+
+// watchUpgrade 监听平滑升级信号 启动新进程并传递监听器 新进程就绪后请求关闭当前进程
+func watchUpgrade(ctx context.Context, conf UpgradeConfig, listeners []namedListener) {
+	if !conf.Enable {
+		return
+	}
+	// 升级完成后继续监听信号直至关闭 避免重复的升级信号以默认行为终止当前进程
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, upgradeSignals[conf.Signal])
+	defer signal.Stop(quit)
+	upgraded := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-quit:
+			if upgraded {
+				continue
+			}
+			pid, err := upgrade(conf, listeners)
+			if err != nil {
+				fmt.Fprintln(os.Stdout, "HTTP Server平滑升级失败, 继续提供服务:", err)
+				continue
+			}
+			upgraded = true
+			fmt.Fprintf(os.Stdout, "HTTP Server新进程[%d]已就绪, 开始关闭当前进程\n", pid)
+			kernel.RequestStop("平滑升级")
+		}
+	}
+}
+
+// upgrade 启动新进程并传递监听器 等待新进程就绪 返回新进程pid
+func upgrade(conf UpgradeConfig, listeners []namedListener) (int, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("获取可执行文件路径错误: %w", err)
+	}
+
+	// 复制监听器fd
+	names := make([]string, 0, len(listeners))
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, nl := range listeners {
+		fl, ok := nl.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return 0, fmt.Errorf("监听器[%s]不支持传递", nl.name)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return 0, fmt.Errorf("复制监听器[%s]错误: %w", nl.name, err)
+		}
+		names = append(names, nl.name)
+		files = append(files, f)
+	}
+
+	// 就绪通知管道
+	r, w, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("创建就绪通知管道错误: %w", err)
+	}
+	defer r.Close()
+	files = append(files, w)
+
+	// 启动新进程
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		// 仅去除监听器传递相关变量 保留密钥与配置覆盖等其余环境变量
+		if !strings.HasPrefix(kv, "LISTEN_") && !strings.HasPrefix(kv, envListeners+"=") && !strings.HasPrefix(kv, envReadyFD+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		envListeners+"="+strings.Join(names, ","),
+		fmt.Sprintf("%s=%d", envReadyFD, listenFDsStart+len(names)),
+	)
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("启动新进程错误: %w", err)
+	}
+	_ = w.Close()
+
+	// 等待新进程就绪 新进程退出时管道读取返回EOF
+	ready := make(chan error, 1)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+	timer := time.NewTimer(conf.ReadyTimeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+		if err == nil {
+			return cmd.Process.Pid, nil
+		}
+		err = fmt.Errorf("新进程[%d]未就绪即退出: %w", cmd.Process.Pid, err)
+	case <-timer.C:
+		err = fmt.Errorf("等待新进程[%d]就绪超时[%s]", cmd.Process.Pid, conf.ReadyTimeout)
+	}
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return 0, err
+}
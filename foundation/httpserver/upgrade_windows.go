@@ -0,0 +1,25 @@
+//go:build windows
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+)
+
+// inheritedListener Windows不支持继承监听器
+func inheritedListener(name string) (net.Listener, bool, error) {
+	return nil, false, nil
+}
+
+// notifyReady Windows不支持平滑升级
+func notifyReady() {}
+
+// watchUpgrade Windows不支持平滑升级
+func watchUpgrade(ctx context.Context, conf UpgradeConfig, listeners []namedListener) {
+	if conf.Enable {
+		fmt.Fprintln(os.Stdout, "当前平台不支持平滑升级, 配置[upgrade.enable]不生效")
+	}
+}
@@ -1,41 +1,64 @@
 package kernel
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
 )
 
 type BootList []func() error
 
+// Bootstrap 引导应用 发生错误时退出进程
 func Bootstrap(confPath string, bootRegister func() BootList) {
+	if err := BootstrapE(confPath, bootRegister); err != nil {
+		fmt.Fprintln(os.Stdout, "引导应用错误:", err)
+		os.Exit(1)
+	}
+}
+
+// BootstrapE 引导应用 返回引导过程中发生的错误
+func BootstrapE(confPath string, bootRegister func() BootList) error {
 	// 加载配置
 	if err := config.Boot(confPath); err != nil {
-		fmt.Fprintln(os.Stdout, "引导加载配置错误:", err)
-		os.Exit(1)
+		return fmt.Errorf("%w: %w", kit.ErrConfigLoad, err)
 	}
 
 	// 检查配置
 	if err := checkConfig(); err != nil {
-		fmt.Fprintln(os.Stdout, "引导配置发现错误:", err)
-		os.Exit(1)
+		return fmt.Errorf("%w: %w", kit.ErrConfigInvalid, err)
+	}
+
+	// 监听配置变更
+	if config.AppConfigWatch() {
+		stop, err := config.Watch()
+		if err != nil {
+			return fmt.Errorf("%w: %w", kit.ErrConfigLoad, err)
+		}
+		OnStop("config[watch]", func(ctx context.Context) error {
+			return stop()
+		})
 	}
 
 	// 引导与加载资源
 	bs := bootRegister()
 	for _, boot := range bs {
 		if err := boot(); err != nil {
-			fmt.Fprintln(os.Stdout, "引导加载资源错误:", err)
-			os.Exit(1)
+			return fmt.Errorf("%w: %w", kit.ErrBootResource, err)
 		}
 	}
+	return nil
 }
 
 func checkConfig() error {
+	// 检测config.yaml是否正确加载
+	if !config.Exist("config") {
+		return errors.New("加载配置文件config.yaml失败, 请检查配置目录")
+	}
 	// 检测app.yaml是否正确
-	config.Pick()
 	if config.AppName() == "" {
 		return errors.New("未配置应用Name, 请在app.yaml[app.name]中配置")
 	}
@@ -0,0 +1,11 @@
+package kernel
+
+import "time"
+
+var DefaultConfig = Config{
+	ShutdownWaitTimeout: 30 * time.Second,
+}
+
+type Config struct {
+	ShutdownWaitTimeout time.Duration `mapstructure:"shutdown_wait_timeout"` // ShutdownWaitTimeout 等待全部托管组件关闭的全局超时时间
+}
@@ -0,0 +1,107 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStopTimeout 关闭钩子默认超时时间
+const DefaultStopTimeout = 5 * time.Second
+
+type stopHook struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+var (
+	stopMu    sync.Mutex
+	stopHooks []stopHook
+	stopOnce  sync.Once
+	stopping  atomic.Bool
+
+	stopRequests = make(chan string, 1)
+)
+
+// Stopping 应用是否已开始关闭
+func Stopping() bool {
+	return stopping.Load()
+}
+
+// markStopping 标记应用开始关闭
+func markStopping() {
+	stopping.Store(true)
+}
+
+// RequestStop 请求应用关闭 效果等同收到结束信号 用于组件主动触发关闭的场景 例: 平滑升级时新进程就绪后关闭旧进程
+// 参数:
+// reason: 关闭原因 用于关闭日志
+func RequestStop(reason string) {
+	select {
+	case stopRequests <- reason:
+	default:
+	}
+}
+
+// OnStop 注册关闭钩子 关闭时按注册顺序的逆序执行
+// 参数:
+// name: 钩子名称 用于关闭日志
+// fn: 关闭逻辑 需在ctx结束前返回
+// timeouts: 钩子超时时间 默认为 DefaultStopTimeout
+func OnStop(name string, fn func(ctx context.Context) error, timeouts ...time.Duration) {
+	timeout := DefaultStopTimeout
+	if len(timeouts) != 0 && timeouts[0] > 0 {
+		timeout = timeouts[0]
+	}
+	stopMu.Lock()
+	defer stopMu.Unlock()
+	stopHooks = append(stopHooks, stopHook{
+		name:    name,
+		timeout: timeout,
+		fn:      fn,
+	})
+}
+
+// Shutdown 按注册顺序的逆序执行全部关闭钩子 多次调用仅执行一次
+func Shutdown() {
+	markStopping()
+	stopOnce.Do(func() {
+		stopMu.Lock()
+		hooks := make([]stopHook, len(stopHooks))
+		copy(hooks, stopHooks)
+		stopMu.Unlock()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := runStopHook(hooks[i]); err != nil {
+				fmt.Fprintf(os.Stdout, "执行关闭钩子[%s]错误: %s\n", hooks[i].name, err)
+			}
+		}
+	})
+}
+
+// runStopHook 在超时时间内执行关闭钩子
+func runStopHook(hook stopHook) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("发生panic: %v", r)
+			}
+		}()
+		done <- hook.fn(ctx)
+	}()
+
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("等待超时[%s]", hook.timeout)
+	}
+}
@@ -7,14 +7,19 @@ import (
 	"syscall"
 )
 
-// ListenStop 监听结束信号并注册处理逻辑器
+// ListenStop 监听结束信号并注册处理逻辑器 处理完成后执行全部关闭钩子
 func ListenStop(handler func() error) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case <-quit:
+	case <-stopRequests:
+	}
+	markStopping()
 	err := handler()
 	if err != nil {
 		fmt.Fprintln(os.Stdout, "关闭处理错误:", err)
 		// os.Exit(1)
 	}
+	Shutdown()
 }
@@ -0,0 +1,136 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+)
+
+// Runnable 可被内核托管运行的组件
+type Runnable interface {
+	// Name 组件名称
+	Name() string
+	// Run 运行组件 阻塞至ctx结束后完成优雅关闭再返回
+	Run(ctx context.Context) error
+}
+
+type runnable struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (r *runnable) Name() string {
+	return r.name
+}
+
+func (r *runnable) Run(ctx context.Context) error {
+	return r.fn(ctx)
+}
+
+// NewRunnable 以函数创建托管组件 例如自定义的队列消费者
+func NewRunnable(name string, fn func(ctx context.Context) error) Runnable {
+	return &runnable{
+		name: name,
+		fn:   fn,
+	}
+}
+
+// CommandRegister 启动托管组件命令注册
+func CommandRegister(runnables ...Runnable) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := Serve(runnables...); err != nil {
+			return fmt.Errorf("%w: %w", kit.ErrServerRun, err)
+		}
+		return nil
+	}
+}
+
+// Serve 在同一进程中托管运行全部组件
+// 收到结束信号或任一组件运行失败时通知全部组件关闭 并在全局超时时间内等待关闭完成 最后执行关闭钩子
+func Serve(runnables ...Runnable) error {
+	// 获取配置
+	conf := DefaultConfig
+	if err := config.UnmarshalKeyStrict(config.Pick(), "kernel", &conf); err != nil {
+		return fmt.Errorf("%w: 解析config.yaml[kernel]错误: %w", kit.ErrDataUnmarshal, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	// 启动全部组件
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(runnables))
+	for _, r := range runnables {
+		go func(r Runnable) {
+			defer func() {
+				if pnc := recover(); pnc != nil {
+					results <- result{name: r.Name(), err: fmt.Errorf("发生panic: %v", pnc)}
+				}
+			}()
+			results <- result{name: r.Name(), err: r.Run(ctx)}
+		}(r)
+	}
+
+	// 等待结束信号或组件运行失败
+	var errs []error
+	remaining := len(runnables)
+wait:
+	for remaining > 0 {
+		select {
+		case sig := <-quit:
+			fmt.Fprintf(os.Stdout, "收到信号[%s], 开始关闭全部组件\n", sig)
+			break wait
+		case reason := <-stopRequests:
+			fmt.Fprintf(os.Stdout, "收到关闭请求[%s], 开始关闭全部组件\n", reason)
+			break wait
+		case res := <-results:
+			remaining--
+			if res.err != nil {
+				errs = append(errs, fmt.Errorf("组件[%s]运行错误: %w", res.name, res.err))
+				fmt.Fprintf(os.Stdout, "组件[%s]运行错误, 开始关闭全部组件: %s\n", res.name, res.err)
+				break wait
+			}
+			fmt.Fprintf(os.Stdout, "组件[%s]已退出\n", res.name)
+		}
+	}
+
+	// 标记开始关闭后通知全部组件关闭并等待
+	markStopping()
+	cancel()
+	timer := time.NewTimer(conf.ShutdownWaitTimeout)
+	defer timer.Stop()
+drain:
+	for remaining > 0 {
+		select {
+		case res := <-results:
+			remaining--
+			if res.err != nil {
+				errs = append(errs, fmt.Errorf("组件[%s]关闭错误: %w", res.name, res.err))
+			}
+		case <-timer.C:
+			errs = append(errs, fmt.Errorf("等待组件关闭超时[%s], 强制关闭", conf.ShutdownWaitTimeout))
+			break drain
+		}
+	}
+
+	// 执行关闭钩子
+	Shutdown()
+
+	return errors.Join(errs...)
+}
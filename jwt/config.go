@@ -1,9 +1,12 @@
 package jwt
 
 import (
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/zjutjh/mygo/config"
 )
 
 var DefaultConfig = Config{
@@ -19,3 +22,17 @@ type Config struct {
 	Issuer     string           `mapstructure:"issuer"`
 	Audience   jwt.ClaimStrings `mapstructure:"audience"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Secret == "" {
+		return errors.New("secret不能为空")
+	}
+	if c.Secret == DefaultConfig.Secret && config.AppEnv() == config.AppEnvProd {
+		return errors.New("prod环境下secret不能使用默认值")
+	}
+	if c.Expiration <= 0 {
+		return errors.New("expiration必须大于0")
+	}
+	return nil
+}
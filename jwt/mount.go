@@ -10,9 +10,15 @@ import (
 
 const MountKey = "_jwt_identity_"
 
-// MountIdentity 挂载 identity 至上下文
+// MountIdentity 挂载 identity 至上下文 可格式化为字符串时同时写入请求上下文 供日志记录
 func MountIdentity[T any](ctx *gin.Context, identity T) {
 	ctx.Set(MountKey, identity)
+	if s := kit.FormatIdentity(identity); s != "" && ctx.Request != nil {
+		ctx.Request = ctx.Request.WithContext(kit.WithIdentity(ctx.Request.Context(), s))
+		if meta, ok := kit.MetaFrom(ctx.Request.Context()); ok {
+			ctx.Set(kit.MetaKey, meta)
+		}
+	}
 }
 
 // GetIdentity 获取上下文中挂载的 identity
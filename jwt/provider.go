@@ -70,6 +70,7 @@ func provide[T any](scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("jwt", scope, conf)
 
 	return nil
 }
@@ -86,11 +87,16 @@ func getConf(scope string) (conf Config, err error) {
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
@@ -0,0 +1,25 @@
+package kit
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff 指数退避 第attempt次失败后的等待时间
+// initial 首次等待时间 非正数时取1s; maxBackoff 最大等待时间 非正数时不限制; multiplier 增长倍数 小于1时取2
+func Backoff(initial, maxBackoff time.Duration, multiplier float64, attempt int) time.Duration {
+	if initial <= 0 {
+		initial = time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(max(attempt, 1)-1))
+	if maxBackoff > 0 && d > float64(maxBackoff) {
+		return maxBackoff
+	}
+	if d > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,37 @@
+package kit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		initial    time.Duration
+		maxBackoff time.Duration
+		multiplier float64
+		attempt    int
+		want       time.Duration
+	}{
+		{"first attempt", time.Second, time.Minute, 2, 1, time.Second},
+		{"attempt below one", time.Second, time.Minute, 2, 0, time.Second},
+		{"exponential", time.Second, time.Minute, 2, 4, 8 * time.Second},
+		{"custom multiplier", 100 * time.Millisecond, time.Minute, 3, 3, 900 * time.Millisecond},
+		{"capped", time.Second, time.Minute, 2, 10, time.Minute},
+		{"default initial", 0, time.Minute, 2, 2, 2 * time.Second},
+		{"default multiplier", time.Second, time.Minute, 0.5, 3, 4 * time.Second},
+		{"multiplier one", time.Second, time.Minute, 1, 5, time.Second},
+		{"no cap", time.Second, 0, 2, 11, 1024 * time.Second},
+		{"overflow without cap", time.Second, 0, 2, 100, time.Duration(math.MaxInt64)},
+		{"overflow with cap", time.Second, time.Hour, 2, 1000, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Backoff(tt.initial, tt.maxBackoff, tt.multiplier, tt.attempt); got != tt.want {
+				t.Fatalf("Backoff = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package kit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 请求上下文相关请求头
+const (
+	HeaderRequestID   = "X-Request-Id"
+	HeaderTraceParent = "traceparent" // W3C Trace Context
+)
+
+// Meta 请求上下文元数据 存储于context.Context 在服务内各组件日志与出站请求中传递
+type Meta struct {
+	RequestID string // RequestID 请求ID
+	Identity  string // Identity 当前请求的身份标识 例: 用户ID
+	TraceID   string // TraceID 链路ID 32位十六进制
+	SpanID    string // SpanID 当前服务的调用ID 16位十六进制
+}
+
+type metaKey struct{}
+
+// MetaKey gin.Context中存储请求上下文元数据的键 gin.Context未开启ContextWithFallback时 MetaFrom 通过该键读取
+const MetaKey = "_kit_meta_"
+
+// NewMeta 创建新的请求上下文元数据 生成请求ID、链路ID与调用ID
+func NewMeta() Meta {
+	return Meta{
+		RequestID: NewRequestID(),
+		TraceID:   randomHex(16),
+		SpanID:    randomHex(8),
+	}
+}
+
+// WithMeta 返回携带请求上下文元数据的ctx
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFrom 获取ctx中的请求上下文元数据
+func MetaFrom(ctx context.Context) (Meta, bool) {
+	if ctx == nil {
+		return Meta{}, false
+	}
+	if meta, ok := ctx.Value(metaKey{}).(Meta); ok {
+		return meta, true
+	}
+	meta, ok := ctx.Value(MetaKey).(Meta)
+	return meta, ok
+}
+
+// EnsureMeta ctx中不存在请求上下文元数据时 返回携带新元数据的ctx
+func EnsureMeta(ctx context.Context) context.Context {
+	if _, ok := MetaFrom(ctx); ok {
+		return ctx
+	}
+	return WithMeta(ctx, NewMeta())
+}
+
+// WithIdentity 返回设置了身份标识的ctx
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	meta, _ := MetaFrom(ctx)
+	meta.Identity = identity
+	return WithMeta(ctx, meta)
+}
+
+// FormatIdentity 将身份标识格式化为字符串 仅支持字符串、整数与fmt.Stringer 其余类型返回空字符串
+func FormatIdentity(identity any) string {
+	switch v := identity.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	}
+	return ""
+}
+
+// RequestID 获取ctx中的请求ID 不存在时返回空字符串
+func RequestID(ctx context.Context) string {
+	meta, _ := MetaFrom(ctx)
+	return meta.RequestID
+}
+
+// NewRequestID 生成请求ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// TraceParent 以W3C Trace Context格式输出链路信息 用于出站请求 链路信息不完整时返回空字符串
+func (m Meta) TraceParent() string {
+	if len(m.TraceID) != 32 || len(m.SpanID) != 16 {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", m.TraceID, m.SpanID)
+}
+
+// ParseTraceParent 解析W3C Trace Context请求头 返回链路ID与上游调用ID
+func ParseTraceParent(header string) (traceID, parentID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if !isHex(parts[1]) || !isHex(parts[2]) || parts[1] == strings.Repeat("0", 32) {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// randomHex 生成n字节的随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,77 @@
+package kit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"valid", "00-" + traceID + "-" + parentID + "-01", true},
+		{"surrounding spaces", " 00-" + traceID + "-" + parentID + "-00 ", true},
+		{"future version with extra fields", "cc-" + traceID + "-" + parentID + "-01-extra", true},
+		{"empty", "", false},
+		{"missing flags", "00-" + traceID + "-" + parentID, false},
+		{"short trace id", "00-" + traceID[:30] + "-" + parentID + "-01", false},
+		{"short parent id", "00-" + traceID + "-" + parentID[:14] + "-01", false},
+		{"non hex trace id", "00-" + "zz" + traceID[2:] + "-" + parentID + "-01", false},
+		{"non hex parent id", "00-" + traceID + "-" + "zz" + parentID[2:] + "-01", false},
+		{"all zero trace id", "00-00000000000000000000000000000000-" + parentID + "-01", false},
+		{"long version", "000-" + traceID + "-" + parentID + "-01", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTrace, gotParent, ok := ParseTraceParent(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("ParseTraceParent(%q) ok = %v, want %v", tt.header, ok, tt.ok)
+			}
+			if ok && (gotTrace != traceID || gotParent != parentID) {
+				t.Fatalf("ParseTraceParent(%q) = %s, %s", tt.header, gotTrace, gotParent)
+			}
+		})
+	}
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	meta := NewMeta()
+	traceID, parentID, ok := ParseTraceParent(meta.TraceParent())
+	if !ok || traceID != meta.TraceID || parentID != meta.SpanID {
+		t.Fatalf("ParseTraceParent(%q) = %s, %s, %v", meta.TraceParent(), traceID, parentID, ok)
+	}
+	if got := (Meta{TraceID: meta.TraceID}).TraceParent(); got != "" {
+		t.Fatalf("TraceParent without span id = %q, want empty", got)
+	}
+}
+
+func TestMetaFrom(t *testing.T) {
+	if _, ok := MetaFrom(context.Background()); ok {
+		t.Fatal("MetaFrom(empty ctx) ok = true")
+	}
+
+	meta := NewMeta()
+	ctx := WithIdentity(WithMeta(context.Background(), meta), "u1")
+	got, ok := MetaFrom(ctx)
+	if !ok || got.RequestID != meta.RequestID || got.Identity != "u1" {
+		t.Fatalf("MetaFrom = %+v, %v", got, ok)
+	}
+	if EnsureMeta(ctx) != ctx {
+		t.Fatal("EnsureMeta replaced existing meta")
+	}
+	if RequestID(EnsureMeta(context.Background())) == "" {
+		t.Fatal("EnsureMeta did not create meta")
+	}
+
+	// gin.Context未开启ContextWithFallback时 元数据以字符串键存储
+	ctx = context.WithValue(context.Background(), MetaKey, meta)
+	if RequestID(ctx) != meta.RequestID {
+		t.Fatal("MetaFrom did not read MetaKey")
+	}
+}
@@ -29,6 +29,15 @@ var (
 	ErrRequestTooFrequently   = errors.New("请求过于频繁")
 )
 
+// 引导运行类通用错误
+var (
+	ErrConfigLoad    = errors.New("加载配置错误")
+	ErrConfigInvalid = errors.New("配置错误")
+	ErrBootResource  = errors.New("引导加载资源错误")
+	ErrServerRun     = errors.New("服务运行错误")
+	ErrCommandRun    = errors.New("命令执行错误")
+)
+
 // NewRequestInvalidParamterError 创建一个具体的HTTP Status Code非成功错误
 func NewHttpStatusCodeNotOKError(code int) error {
 	return fmt.Errorf("%w: HTTP Status Code[%d]", ErrHttpStatusCodeNotOK, code)
@@ -8,3 +8,8 @@ type Config struct {
 	// 基础依赖组件实例配置
 	Redis string `mapstructure:"redis"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 )
 
 // New 以指定配置创建实例
+// 连接池复用nedis实例 随nedis关闭钩子一同释放
 func New(conf Config) *redsync.Redsync {
 	rdb := nedis.Pick(conf.Redis)
 	pool := goredis.NewPool(rdb)
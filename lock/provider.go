@@ -1,6 +1,7 @@
 package lock
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/go-redsync/redsync/v4"
@@ -8,7 +9,9 @@ import (
 	"github.com/samber/do"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/health"
 	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nedis"
 )
 
 const (
@@ -59,6 +62,12 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("lock", scope, conf)
+
+	// 注册健康检查
+	health.Register(fmt.Sprintf("lock[%s]", scope), func(ctx context.Context) error {
+		return nedis.Pick(conf.Redis).Ping(ctx).Err()
+	})
 
 	return nil
 }
@@ -75,11 +84,16 @@ func getConf(scope string) (conf Config, err error) {
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
@@ -1,6 +1,7 @@
 package cors
 
 import (
+	"errors"
 	"time"
 )
 
@@ -39,3 +40,11 @@ type Config struct {
 	AllowFiles                bool          `mapstructure:"allow_files"`
 	OptionsResponseStatusCode int           `mapstructure:"options_response_status_code"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.AllowAllOrigins && len(c.AllowOrigins) != 0 {
+		return errors.New("allow_all_origins与allow_origins不能同时配置")
+	}
+	return nil
+}
@@ -1,9 +1,14 @@
 package cors
 
 import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
+	"github.com/spf13/viper"
 
 	"github.com/zjutjh/mygo/config"
 	"github.com/zjutjh/mygo/kit"
@@ -11,26 +16,73 @@ import (
 
 const defaultConfigKey = "mid_cors"
 
-// Pick 获取指定实例
+// Pick 获取指定实例 开启配置监听时随配置变更热更新
 func Pick(keys ...string) gin.HandlerFunc {
 	key := defaultConfigKey
 	if len(keys) != 0 && keys[0] != "" {
 		key = keys[0]
 	}
-	conf := Config{}
-	err := copier.Copy(&conf, DefaultConfig)
+	handler, err := newHandler(key)
 	if err != nil {
 		panic(err)
 	}
-	app := config.Pick()
-	if !app.IsSet(key) {
-		panic(kit.ErrNotFound)
+
+	current := atomic.Pointer[gin.HandlerFunc]{}
+	current.Store(&handler)
+	// 非法变更被拒绝并保留原配置
+	config.OnValidate("", func(v *viper.Viper) error {
+		_, err := getConf(v, key)
+		return err
+	})
+	config.OnChange("", key, func(v *viper.Viper) {
+		h, err := newHandler(key)
+		if err != nil {
+			fmt.Fprintf(os.Stdout, "热更新CORS中间件[%s]错误, 保留原配置: %s\n", key, err)
+			return
+		}
+		current.Store(&h)
+	})
+
+	return func(ctx *gin.Context) {
+		(*current.Load())(ctx)
 	}
-	err = app.UnmarshalKey(key, &conf)
+}
+
+// newHandler 以config.yaml[{key}]创建中间件
+func newHandler(key string) (gin.HandlerFunc, error) {
+	conf, err := getConf(config.Pick(), key)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return cors.New(cors.Config{
+	config.Track("mid_cors", key, conf)
+	return cors.New(toCorsConfig(conf)), nil
+}
+
+// getConf 从配置实例中解析并校验配置 包含cors.Config自身的校验 避免cors.New发生panic
+func getConf(v *viper.Viper, key string) (conf Config, err error) {
+	err = copier.Copy(&conf, DefaultConfig)
+	if err != nil {
+		return conf, err
+	}
+	if !v.IsSet(key) {
+		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, key)
+	}
+	err = config.UnmarshalKeyStrict(v, key, &conf)
+	if err != nil {
+		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, key, err)
+	}
+	if err = conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, key, err)
+	}
+	if err = toCorsConfig(conf).Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, key, err)
+	}
+	return conf, nil
+}
+
+// toCorsConfig 转换为cors.Config
+func toCorsConfig(conf Config) cors.Config {
+	return cors.Config{
 		AllowAllOrigins:           conf.AllowAllOrigins,
 		AllowOrigins:              conf.AllowOrigins,
 		AllowMethods:              conf.AllowMethods,
@@ -45,5 +97,5 @@ func Pick(keys ...string) gin.HandlerFunc {
 		AllowWebSockets:           conf.AllowWebSockets,
 		AllowFiles:                conf.AllowFiles,
 		OptionsResponseStatusCode: conf.OptionsResponseStatusCode,
-	})
+	}
 }
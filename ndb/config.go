@@ -1,6 +1,7 @@
 package ndb
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm/logger"
@@ -110,3 +111,17 @@ type Config struct {
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Host == "" {
+		return errors.New("host不能为空")
+	}
+	if c.Port <= 0 {
+		return errors.New("port必须大于0")
+	}
+	if c.Database == "" {
+		return errors.New("database不能为空")
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package ndb
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -9,6 +10,8 @@ import (
 	"github.com/samber/do"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/health"
+	"github.com/zjutjh/mygo/foundation/kernel"
 	"github.com/zjutjh/mygo/kit"
 )
 
@@ -63,6 +66,25 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("ndb", scope, conf)
+
+	// 注册健康检查
+	health.Register(fmt.Sprintf("ndb[%s]", scope), func(ctx context.Context) error {
+		sd, err := instance.DB()
+		if err != nil {
+			return err
+		}
+		return sd.PingContext(ctx)
+	})
+
+	// 注册关闭钩子
+	kernel.OnStop(fmt.Sprintf("ndb[%s]", scope), func(ctx context.Context) error {
+		sd, err := instance.DB()
+		if err != nil {
+			return err
+		}
+		return sd.Close()
+	})
 
 	return nil
 }
@@ -79,11 +101,16 @@ func getConf(scope string) (conf Config, err error) {
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
@@ -1,6 +1,8 @@
 package nedis
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/redis/go-redis/v9/maintnotifications"
@@ -145,3 +147,19 @@ type MaintnotificationsConfig struct {
 	CircuitBreakerMaxRequests      int                             `mapstructure:"circuit_breaker_max_requests"`
 	MaxHandoffRetries              int                             `mapstructure:"max_handoff_retries"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if len(c.Addrs) == 0 {
+		return errors.New("addrs不能为空")
+	}
+	switch c.Mode {
+	case ModeSingle, ModeCluster, ModeFailover:
+	default:
+		return fmt.Errorf("mode[%s]非法, 可选值为%s/%s/%s", c.Mode, ModeSingle, ModeCluster, ModeFailover)
+	}
+	if c.Mode == ModeFailover && c.MasterName == "" {
+		return errors.New("failover模式下master_name不能为空")
+	}
+	return nil
+}
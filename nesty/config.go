@@ -1,6 +1,9 @@
 package nesty
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 var DefaultConfig = Config{
 	Log:            "",
@@ -9,6 +12,8 @@ var DefaultConfig = Config{
 
 	Timeout: 5 * time.Second,
 
+	HealthCheckURL: "",
+
 	TLSHandshakeTimeout:    0,
 	DisableKeepAlives:      false,
 	DisableCompression:     false,
@@ -37,6 +42,9 @@ type Config struct {
 
 	Timeout time.Duration `mapstructure:"timeout"`
 
+	// HealthCheckURL 健康检查请求的目标地址 为空时不注册健康检查
+	HealthCheckURL string `mapstructure:"health_check_url"`
+
 	// HTTP Client Transport配置
 	TLSHandshakeTimeout    time.Duration `mapstructure:"tls_handshake_timeout"`
 	DisableKeepAlives      bool          `mapstructure:"disable_keep_alives"`
@@ -59,3 +67,14 @@ type Config struct {
 	RetryWaitTime    time.Duration `mapstructure:"retry_wait_time"`
 	RetryMaxWaitTime time.Duration `mapstructure:"retry_max_wait_time"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("timeout不能为负数")
+	}
+	if c.RetryCount < 0 {
+		return errors.New("retry_count不能为负数")
+	}
+	return nil
+}
@@ -4,20 +4,26 @@ import (
 	"errors"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 	"github.com/sirupsen/logrus"
+
+	"github.com/zjutjh/mygo/kit"
 )
 
 func onBeforeRequest() func(client *resty.Client, request *resty.Request) error {
 	return func(client *resty.Client, request *resty.Request) error {
+		meta, ok := kit.MetaFrom(request.Context())
+		if !ok {
+			return nil
+		}
 		// 设置X-Request-Id
-		if request.Header.Get("X-Request-Id") == "" {
-			ctx := request.Context()
-			if ctx2, ok := ctx.(*gin.Context); ok {
-				if rid := ctx2.GetString("X-Request-Id"); rid != "" {
-					request.SetHeader("X-Request-Id", rid)
-				}
+		if request.Header.Get(kit.HeaderRequestID) == "" && meta.RequestID != "" {
+			request.SetHeader(kit.HeaderRequestID, meta.RequestID)
+		}
+		// 设置traceparent 下游沿用当前链路ID 以当前调用ID作为父调用ID
+		if request.Header.Get(kit.HeaderTraceParent) == "" {
+			if tp := meta.TraceParent(); tp != "" {
+				request.SetHeader(kit.HeaderTraceParent, tp)
 			}
 		}
 		return nil
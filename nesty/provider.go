@@ -1,13 +1,16 @@
 package nesty
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/jinzhu/copier"
 	"github.com/samber/do"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/health"
 	"github.com/zjutjh/mygo/kit"
 )
 
@@ -59,6 +62,21 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("nesty", scope, conf)
+
+	// 注册健康检查
+	if conf.HealthCheckURL != "" {
+		health.Register(fmt.Sprintf("nesty[%s]", scope), func(ctx context.Context) error {
+			resp, err := instance.R().SetContext(ctx).Get(conf.HealthCheckURL)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode() >= http.StatusInternalServerError {
+				return kit.NewHttpStatusCodeNotOKError(resp.StatusCode())
+			}
+			return nil
+		})
+	}
 
 	return nil
 }
@@ -75,11 +93,16 @@ func getConf(scope string) (conf Config, err error) {
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
@@ -1,6 +1,10 @@
 package nlog
 
-import "github.com/sirupsen/logrus"
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
 
 var DefaultConfig = Config{
 	Filename:   "./logs/app.log",
@@ -39,3 +43,11 @@ type FeishuHookConfig struct {
 	Feishu       string         `mapstructure:"feishu"`
 	NoticeLevels []logrus.Level `mapstructure:"notice_levels"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Filename == "" {
+		return errors.New("filename不能为空")
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package nlog
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"github.com/zjutjh/mygo/kit"
 )
 
 type hookField struct {
@@ -37,11 +39,23 @@ func (h *hookField) Fire(entry *logrus.Entry) error {
 			entry.Data["client_ip"] = ctx.ClientIP()
 			entry.Data["uri"] = ctx.Request.Host + ctx.Request.RequestURI
 			entry.Data["method"] = ctx.Request.Method
-			if rid := ctx.GetHeader("X-Request-Id"); rid != "" {
-				entry.Data["request_id"] = rid
-			}
+			setField(entry.Data, "request_id", ctx.GetHeader(kit.HeaderRequestID))
+		}
+		// 请求上下文元数据
+		if meta, ok := kit.MetaFrom(entry.Context); ok {
+			setField(entry.Data, "request_id", meta.RequestID)
+			setField(entry.Data, "identity", meta.Identity)
+			setField(entry.Data, "trace_id", meta.TraceID)
+			setField(entry.Data, "span_id", meta.SpanID)
 		}
 	}
 
 	return nil
 }
+
+// setField 设置非空字段
+func setField(data logrus.Fields, key, value string) {
+	if value != "" {
+		data[key] = value
+	}
+}
@@ -1,13 +1,18 @@
 package nlog
 
 import (
+	"context"
 	"fmt"
 
+	"gopkg.in/natefinch/lumberjack.v2"
+
 	"github.com/jinzhu/copier"
 	"github.com/samber/do"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 
 	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/foundation/kernel"
 	"github.com/zjutjh/mygo/kit"
 )
 
@@ -59,6 +64,37 @@ func provide(scope string) error {
 
 	// 挂载实例
 	do.ProvideNamedValue(nil, iocPrefix+scope, instance)
+	config.Track("nlog", scope, conf)
+
+	// 订阅配置变更 热更新日志等级
+	config.OnValidate("", func(v *viper.Viper) error {
+		c, err := defaultConfig()
+		if err != nil {
+			return err
+		}
+		if err = config.UnmarshalKeyStrict(v, scope, &c); err != nil {
+			return fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
+		}
+		if err = c.Validate(); err != nil {
+			return fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+		}
+		return nil
+	})
+	config.OnChange("", scope+".level", func(v *viper.Viper) {
+		conf, err := getConf(scope)
+		if err != nil {
+			return
+		}
+		instance.SetLevel(conf.Level)
+		config.Track("nlog", scope, conf)
+	})
+
+	// 注册关闭钩子 日志实例最先加载 因此最后关闭
+	if w, ok := instance.Out.(*lumberjack.Logger); ok {
+		kernel.OnStop(fmt.Sprintf("nlog[%s]", scope), func(ctx context.Context) error {
+			return w.Close()
+		})
+	}
 
 	return nil
 }
@@ -75,11 +111,16 @@ func getConf(scope string) (conf Config, err error) {
 	if !cfg.IsSet(scope) {
 		return conf, fmt.Errorf("%w: 配置config.yaml[%s]不存在", kit.ErrNotFound, scope)
 	}
-	// 解析 config.yaml[{scope}]
-	err = cfg.UnmarshalKey(scope, &conf)
+	// 严格解析 config.yaml[{scope}]
+	err = config.UnmarshalKeyStrict(cfg, scope, &conf)
 	if err != nil {
 		return conf, fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, scope, err)
 	}
+	// 校验配置
+	err = conf.Validate()
+	if err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, scope, err)
+	}
 	return conf, nil
 }
 
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"errors"
+	"time"
+)
+
+var DefaultConfig = Config{
+	ShutdownWaitTimeout: 30 * time.Second,
+	Logger:              "",
+	Redis:               "redis",
+	Group:               "",
+	Consumer:            "",
+
+	Concurrency: 1,
+	MaxRetries:  3,
+	Timeout:     0,
+
+	BlockTimeout:      2 * time.Second,
+	VisibilityTimeout: 5 * time.Minute,
+	ReclaimInterval:   30 * time.Second,
+	PollInterval:      time.Second,
+	UniqueTTL:         24 * time.Hour,
+
+	Backoff: BackoffConfig{
+		Initial:    time.Second,
+		Max:        10 * time.Minute,
+		Multiplier: 2,
+	},
+	DeadLetter: DeadLetterConfig{
+		MaxLen: 10000,
+	},
+}
+
+type Config struct {
+	ShutdownWaitTimeout time.Duration `mapstructure:"shutdown_wait_timeout"` // ShutdownWaitTimeout 关闭时等待运行中任务处理完成的时间 超时后取消任务ctx
+	Logger              string        `mapstructure:"logger"`                // Logger 任务运行日志使用的nlog实例scope
+	Redis               string        `mapstructure:"redis"`                 // Redis 存储队列的nedis实例scope
+	Group               string        `mapstructure:"group"`                 // Group 消费组名 为空时取应用名
+	Consumer            string        `mapstructure:"consumer"`              // Consumer 消费者名 为空时取 {hostname}-{pid}
+
+	Concurrency int           `mapstructure:"concurrency"` // Concurrency 每个主题默认的并发处理数
+	MaxRetries  int           `mapstructure:"max_retries"` // MaxRetries 任务默认的最大重试次数 超过后转入死信流
+	Timeout     time.Duration `mapstructure:"timeout"`     // Timeout 任务默认的单次处理超时时间 为0时不限制
+
+	BlockTimeout      time.Duration `mapstructure:"block_timeout"`      // BlockTimeout 单次拉取任务的阻塞等待时间
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"` // VisibilityTimeout 任务超过该时间未确认且未续期时(例: 消费者进程退出) 由其他消费者重新认领处理 处理中的任务每1/3该时间续期一次
+	ReclaimInterval   time.Duration `mapstructure:"reclaim_interval"`   // ReclaimInterval 检查超时未确认任务的间隔
+	PollInterval      time.Duration `mapstructure:"poll_interval"`      // PollInterval 检查到期延迟任务的间隔
+	UniqueTTL         time.Duration `mapstructure:"unique_ttl"`         // UniqueTTL 任务唯一键默认有效期 任务处理完成或转入死信流时提前释放
+
+	Backoff    BackoffConfig    `mapstructure:"backoff"`
+	DeadLetter DeadLetterConfig `mapstructure:"dead_letter"`
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Concurrency <= 0 {
+		return errors.New("并发处理数[concurrency]必须大于0")
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("最大重试次数[max_retries]不能为负数")
+	}
+	if c.Timeout < 0 {
+		return errors.New("处理超时时间[timeout]不能为负数")
+	}
+	if c.BlockTimeout <= 0 || c.VisibilityTimeout <= 0 || c.ReclaimInterval <= 0 || c.PollInterval <= 0 {
+		return errors.New("拉取阻塞时间[block_timeout]、可见性超时[visibility_timeout]、认领间隔[reclaim_interval]与延迟任务检查间隔[poll_interval]必须大于0")
+	}
+	if c.UniqueTTL <= 0 {
+		return errors.New("唯一键有效期[unique_ttl]必须大于0")
+	}
+	if c.Backoff.Initial < 0 || c.Backoff.Max < 0 || (c.Backoff.Multiplier != 0 && c.Backoff.Multiplier < 1) {
+		return errors.New("重试退避配置[backoff]错误 等待时间不能为负数且增长倍数不能小于1")
+	}
+	if c.DeadLetter.MaxLen < 0 {
+		return errors.New("死信流最大长度[dead_letter.max_len]不能为负数")
+	}
+	return nil
+}
+
+type BackoffConfig struct {
+	Initial    time.Duration `mapstructure:"initial"`    // Initial 首次重试前等待时间
+	Max        time.Duration `mapstructure:"max"`        // Max 重试前最大等待时间
+	Multiplier float64       `mapstructure:"multiplier"` // Multiplier 等待时间增长倍数
+}
+
+type DeadLetterConfig struct {
+	MaxLen int64 `mapstructure:"max_len"` // MaxLen 死信流保留的最大任务数(近似) 为0时不限制
+}
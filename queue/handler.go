@@ -0,0 +1,144 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zjutjh/mygo/kit"
+)
+
+// ErrSkipRetry 处理器返回包装该错误的错误时 任务不再重试 直接转入死信流
+var ErrSkipRetry = errors.New("任务不再重试")
+
+// Task 任务运行信息
+type Task struct {
+	ID         string    // ID 任务ID 投递时生成 重试时不变
+	Topic      string    // Topic 任务主题名
+	MessageID  string    // MessageID 本次运行对应的Stream消息ID
+	Attempt    int       // Attempt 本次为第几次运行 从1开始
+	MaxRetries int       // MaxRetries 最大重试次数
+	EnqueuedAt time.Time // EnqueuedAt 首次投递时间
+	Unique     string    // Unique 任务唯一键 未指定时为空
+}
+
+type taskKey struct{}
+
+// TaskFrom 从处理器ctx中获取任务运行信息
+func TaskFrom(ctx context.Context) (Task, bool) {
+	t, ok := ctx.Value(taskKey{}).(Task)
+	return t, ok
+}
+
+// handler 主题任务处理器
+type handler struct {
+	topic       string
+	run         func(ctx context.Context, payload []byte) error
+	concurrency int
+	maxRetries  int
+	timeout     time.Duration
+}
+
+// HandleOption 任务处理器选项 未指定的选项取 config.yaml[queue] 中的默认值
+type HandleOption func(h *handler)
+
+// WithConcurrency 指定主题并发处理数
+func WithConcurrency(n int) HandleOption {
+	return func(h *handler) {
+		h.concurrency = n
+	}
+}
+
+// WithMaxRetries 指定任务最大重试次数 为0时失败后直接转入死信流
+func WithMaxRetries(n int) HandleOption {
+	return func(h *handler) {
+		h.maxRetries = n
+	}
+}
+
+// WithTimeout 指定任务单次处理超时时间
+func WithTimeout(timeout time.Duration) HandleOption {
+	return func(h *handler) {
+		h.timeout = timeout
+	}
+}
+
+var (
+	handlerMu sync.RWMutex
+	handlers  []handler
+)
+
+// Handle 注册主题任务处理器 处理器在任务队列服务启动时开始消费
+// 处理器返回错误时按退避策略重试 超过最大重试次数或返回 ErrSkipRetry 时转入死信流
+func Handle[T any](topic Topic[T], fn func(ctx context.Context, payload T) error, opts ...HandleOption) error {
+	if topic.name == "" {
+		return fmt.Errorf("%w: 任务主题名为空", kit.ErrDataFormat)
+	}
+	if fn == nil {
+		return fmt.Errorf("%w: 任务主题[%s]未设置处理逻辑", kit.ErrDataFormat, topic.name)
+	}
+	h := handler{
+		topic: topic.name,
+		run: func(ctx context.Context, data []byte) error {
+			var payload T
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return fmt.Errorf("%w: %w: 任务主题[%s]载荷: %w", ErrSkipRetry, kit.ErrDataUnmarshal, topic.name, err)
+			}
+			return fn(ctx, payload)
+		},
+		concurrency: -1,
+		maxRetries:  -1,
+		timeout:     -1,
+	}
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	for _, exist := range handlers {
+		if exist.topic == h.topic {
+			return fmt.Errorf("%w: 任务主题[%s]处理器", kit.ErrAlreadyExists, h.topic)
+		}
+	}
+	handlers = append(handlers, h)
+	return nil
+}
+
+// MustHandle 注册主题任务处理器 注册失败时panic
+func MustHandle[T any](topic Topic[T], fn func(ctx context.Context, payload T) error, opts ...HandleOption) {
+	if err := Handle(topic, fn, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Topics 获取已注册处理器的全部主题名
+func Topics() []string {
+	handlerMu.RLock()
+	defer handlerMu.RUnlock()
+	res := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		res = append(res, h.topic)
+	}
+	return res
+}
+
+// resolve 以配置补全处理器中未指定的选项并校验
+func (h handler) resolve(conf Config) (handler, error) {
+	if h.concurrency < 0 {
+		h.concurrency = conf.Concurrency
+	}
+	if h.maxRetries < 0 {
+		h.maxRetries = conf.MaxRetries
+	}
+	if h.timeout < 0 {
+		h.timeout = conf.Timeout
+	}
+	if h.concurrency == 0 {
+		return h, fmt.Errorf("任务主题[%s]并发处理数必须大于0", h.topic)
+	}
+	return h, nil
+}
@@ -0,0 +1,258 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nedis"
+)
+
+// ErrDuplicate 任务唯一键已存在 相同唯一键的任务尚未处理完成
+var ErrDuplicate = fmt.Errorf("%w: 任务唯一键", kit.ErrAlreadyExists)
+
+// Topic 类型化任务主题 任务载荷以JSON编码
+// 例: var SendSMS = queue.NewTopic[SMS]("send_sms")
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic 创建任务主题
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name 主题名
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Enqueue 投递任务 立即可被处理 返回任务ID
+func (t Topic[T]) Enqueue(ctx context.Context, payload T, opts ...EnqueueOption) (string, error) {
+	return t.enqueue(ctx, payload, time.Time{}, opts)
+}
+
+// EnqueueIn 投递延迟任务 在delay后可被处理 返回任务ID
+func (t Topic[T]) EnqueueIn(ctx context.Context, payload T, delay time.Duration, opts ...EnqueueOption) (string, error) {
+	return t.enqueue(ctx, payload, time.Now().Add(delay), opts)
+}
+
+// EnqueueAt 投递定时任务 在at时刻后可被处理 返回任务ID
+func (t Topic[T]) EnqueueAt(ctx context.Context, payload T, at time.Time, opts ...EnqueueOption) (string, error) {
+	return t.enqueue(ctx, payload, at, opts)
+}
+
+func (t Topic[T]) enqueue(ctx context.Context, payload T, at time.Time, opts []EnqueueOption) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: 任务主题[%s]载荷: %w", kit.ErrDataMarshal, t.name, err)
+	}
+	return enqueue(ctx, t.name, data, at, opts)
+}
+
+type enqueueOptions struct {
+	unique    string
+	uniqueTTL time.Duration
+}
+
+// EnqueueOption 任务投递选项
+type EnqueueOption func(o *enqueueOptions)
+
+// WithUnique 指定任务唯一键 相同主题下唯一键相同的任务在处理完成前仅能投递一次 重复投递返回 ErrDuplicate
+// ttl为唯一键有效期 为0时取 config.yaml[queue.unique_ttl]
+func WithUnique(key string, ttl time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.unique = key
+		o.uniqueTTL = ttl
+	}
+}
+
+// message 队列中的任务消息 以字符串编码字段便于在Lua脚本中直接写入Stream
+type message struct {
+	ID         string `json:"id"`
+	Payload    string `json:"payload"`
+	Attempt    int    `json:"attempt,string"`     // Attempt 已运行次数
+	EnqueuedAt int64  `json:"enqueued_at,string"` // EnqueuedAt 首次投递时间 毫秒时间戳
+	Unique     string `json:"unique"`
+	RequestID  string `json:"request_id"` // RequestID 投递方请求ID
+	TraceID    string `json:"trace_id"`   // TraceID 投递方链路ID
+}
+
+// values 转换为Stream消息字段
+func (m message) values() []any {
+	return []any{
+		"id", m.ID,
+		"payload", m.Payload,
+		"attempt", strconv.Itoa(m.Attempt),
+		"enqueued_at", strconv.FormatInt(m.EnqueuedAt, 10),
+		"unique", m.Unique,
+		"request_id", m.RequestID,
+		"trace_id", m.TraceID,
+	}
+}
+
+// parseMessage 解析Stream消息
+func parseMessage(msg redis.XMessage) (message, error) {
+	str := func(key string) string {
+		v, _ := msg.Values[key].(string)
+		return v
+	}
+	m := message{
+		ID:        str("id"),
+		Payload:   str("payload"),
+		Unique:    str("unique"),
+		RequestID: str("request_id"),
+		TraceID:   str("trace_id"),
+	}
+	if m.ID == "" {
+		return m, fmt.Errorf("%w: 消息[%s]缺少任务ID", kit.ErrDataFormat, msg.ID)
+	}
+	var err error
+	if m.Attempt, err = strconv.Atoi(str("attempt")); err != nil {
+		return m, fmt.Errorf("%w: 消息[%s]运行次数: %w", kit.ErrDataFormat, msg.ID, err)
+	}
+	if m.EnqueuedAt, err = strconv.ParseInt(str("enqueued_at"), 10, 64); err != nil {
+		return m, fmt.Errorf("%w: 消息[%s]投递时间: %w", kit.ErrDataFormat, msg.ID, err)
+	}
+	return m, nil
+}
+
+// enqueue 投递任务 at为零值时立即投递 否则写入延迟集合
+func enqueue(ctx context.Context, topic string, payload []byte, at time.Time, opts []EnqueueOption) (string, error) {
+	if topic == "" {
+		return "", fmt.Errorf("%w: 任务主题名为空", kit.ErrDataFormat)
+	}
+	conf, err := currentConf()
+	if err != nil {
+		return "", err
+	}
+	o := enqueueOptions{uniqueTTL: conf.UniqueTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.uniqueTTL <= 0 {
+		o.uniqueTTL = conf.UniqueTTL
+	}
+
+	m := message{ID: newID(), Payload: string(payload), EnqueuedAt: time.Now().UnixMilli(), Unique: o.unique}
+	if meta, ok := kit.MetaFrom(ctx); ok {
+		m.RequestID, m.TraceID = meta.RequestID, meta.TraceID
+	}
+	rdb := nedis.Pick(conf.Redis)
+
+	// 占用唯一键
+	if m.Unique != "" {
+		ok, err := rdb.SetNX(ctx, uniqueKey(topic, m.Unique), m.ID, o.uniqueTTL).Result()
+		if err != nil {
+			return "", fmt.Errorf("任务主题[%s]占用唯一键[%s]错误: %w", topic, m.Unique, err)
+		}
+		if !ok {
+			return "", fmt.Errorf("%w: 任务主题[%s]唯一键[%s]", ErrDuplicate, topic, m.Unique)
+		}
+	}
+
+	if at.IsZero() || !at.After(time.Now()) {
+		err = rdb.XAdd(ctx, &redis.XAddArgs{Stream: streamKey(topic), Values: m.values()}).Err()
+	} else {
+		err = schedule(ctx, rdb, topic, m, at)
+	}
+	if err != nil {
+		if m.Unique != "" {
+			_ = releaseScript.Run(ctx, rdb, []string{uniqueKey(topic, m.Unique)}, m.ID).Err()
+		}
+		return "", fmt.Errorf("投递任务主题[%s]错误: %w", topic, err)
+	}
+	return m.ID, nil
+}
+
+// schedule 将任务写入延迟集合 到期后由worker移入Stream
+func schedule(ctx context.Context, rdb redis.Cmdable, topic string, m message, at time.Time) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return rdb.ZAdd(ctx, delayedKey(topic), redis.Z{Score: float64(at.UnixMilli()), Member: data}).Err()
+}
+
+// releaseScript 仅在唯一键仍属于该任务时释放
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// newID 生成任务ID
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// 主题相关Key以 {topic} 作为hash tag 保证集群模式下同一主题的Key位于同一slot
+
+// streamKey 主题任务Stream的Redis Key
+func streamKey(topic string) string {
+	return fmt.Sprintf("%s:queue:{%s}", config.AppName(), topic)
+}
+
+// delayedKey 主题延迟任务有序集合的Redis Key
+func delayedKey(topic string) string {
+	return streamKey(topic) + ":delayed"
+}
+
+// deadKey 主题死信Stream的Redis Key
+func deadKey(topic string) string {
+	return streamKey(topic) + ":dead"
+}
+
+// uniqueKey 主题任务唯一键的Redis Key
+func uniqueKey(topic, key string) string {
+	return streamKey(topic) + ":unique:" + key
+}
+
+var (
+	confMu sync.Mutex
+	cached *Config
+)
+
+// currentConf 获取config.yaml[queue]配置 首次获取成功后缓存
+func currentConf() (Config, error) {
+	confMu.Lock()
+	defer confMu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	c, err := getConf()
+	if err != nil {
+		return c, err
+	}
+	config.Track("queue", "queue", c)
+	cached = &c
+	return c, nil
+}
+
+func init() {
+	config.TrackFunc("queue", "queue", func() (any, error) { return getConf() })
+}
+
+// getConf 获取config.yaml[queue]配置
+func getConf() (Config, error) {
+	conf := DefaultConfig
+	if err := config.UnmarshalKeyStrict(config.Pick(), "queue", &conf); err != nil {
+		return conf, fmt.Errorf("%w: 解析config.yaml[queue]错误: %w", kit.ErrDataUnmarshal, err)
+	}
+	if err := conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%w: 校验config.yaml[queue]错误: %w", kit.ErrConfigInvalid, err)
+	}
+	return conf, nil
+}
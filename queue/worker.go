@@ -0,0 +1,427 @@
+package queue
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/zjutjh/mygo/config"
+	"github.com/zjutjh/mygo/feishu"
+	"github.com/zjutjh/mygo/foundation/kernel"
+	"github.com/zjutjh/mygo/kit"
+	"github.com/zjutjh/mygo/nedis"
+	"github.com/zjutjh/mygo/nlog"
+)
+
+// 任务处理结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeRetry   = "retry"
+	OutcomeDead    = "dead"
+)
+
+// redisTimeout 确认、重试与转入死信流等Redis操作的超时时间
+const redisTimeout = 3 * time.Second
+
+// promoteBatch 单次移入Stream的到期延迟任务数
+const promoteBatch = 100
+
+// promoteScript 将到期的延迟任务移入Stream
+var promoteScript = redis.NewScript(`
+local items = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, item in ipairs(items) do
+	local m = cjson.decode(item)
+	redis.call("XADD", KEYS[2], "*", "id", m.id, "payload", m.payload, "attempt", m.attempt, "enqueued_at", m.enqueued_at, "unique", m.unique, "request_id", m.request_id or "", "trace_id", m.trace_id or "")
+	redis.call("ZREM", KEYS[1], item)
+end
+return #items
+`)
+
+// CommandRegister 启动任务队列命令注册
+func CommandRegister() func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return RunE()
+	}
+}
+
+// Run 启动任务队列 发生错误时退出进程
+func Run() {
+	if err := RunE(); err != nil {
+		fmt.Fprintln(os.Stdout, "Queue运行错误:", err)
+		os.Exit(1)
+	}
+}
+
+// RunE 启动任务队列 返回运行过程中发生的错误
+func RunE() error {
+	if err := kernel.Serve(NewRunnable()); err != nil {
+		return fmt.Errorf("%w: %w", kit.ErrServerRun, err)
+	}
+	return nil
+}
+
+// NewRunnable 创建可被内核托管的任务队列组件 消费通过 Handle 注册的全部主题
+func NewRunnable() kernel.Runnable {
+	return kernel.NewRunnable("queue", serve)
+}
+
+// worker 任务队列消费者
+type worker struct {
+	conf     Config
+	rdb      redis.UniversalClient
+	logger   *logrus.Logger
+	group    string
+	consumer string
+}
+
+// serve 消费任务 直至ctx结束后等待运行中的任务处理完成
+func serve(ctx context.Context) error {
+	// 获取配置
+	conf, err := getConf()
+	if err != nil {
+		return err
+	}
+	config.Track("queue", "queue", conf)
+	confMu.Lock()
+	cached = &conf
+	confMu.Unlock()
+
+	handlerMu.RLock()
+	hs := make([]handler, 0, len(handlers))
+	for _, h := range handlers {
+		if h, err = h.resolve(conf); err != nil {
+			handlerMu.RUnlock()
+			return err
+		}
+		hs = append(hs, h)
+	}
+	handlerMu.RUnlock()
+	if len(hs) == 0 {
+		return errors.New("未注册任务处理器")
+	}
+
+	// 初始化消费者
+	w := worker{
+		conf:     conf,
+		rdb:      nedis.Pick(conf.Redis),
+		logger:   nlog.Pick(conf.Logger),
+		group:    conf.Group,
+		consumer: conf.Consumer,
+	}
+	if w.group == "" {
+		w.group = config.AppName()
+	}
+	if w.consumer == "" {
+		hostname, _ := os.Hostname()
+		w.consumer = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	for _, h := range hs {
+		if err := w.createGroup(ctx, h.topic); err != nil {
+			return err
+		}
+	}
+
+	// 启动消费 处理中的任务使用独立ctx 服务关闭时继续处理直至等待超时
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+	var wg sync.WaitGroup
+	spawn := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	for _, h := range hs {
+		for range h.concurrency {
+			spawn(func() { w.consume(ctx, workCtx, h) })
+		}
+		spawn(func() { w.loop(ctx, conf.PollInterval, func() { w.promote(h.topic) }) })
+		spawn(func() { w.loop(ctx, conf.ReclaimInterval, func() { w.reclaim(h) }) })
+	}
+
+	// 等待关闭服务
+	<-ctx.Done()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(conf.ShutdownWaitTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		fmt.Fprintln(os.Stdout, "Queue关闭完成")
+		return nil
+	case <-timer.C:
+		cancelWork()
+		return errors.New("queue等待任务处理完成超时, 强制关闭")
+	}
+}
+
+// createGroup 创建主题消费组 消费组已存在时忽略
+func (w worker) createGroup(ctx context.Context, topic string) error {
+	err := w.rdb.XGroupCreateMkStream(ctx, streamKey(topic), w.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("创建任务主题[%s]消费组[%s]错误: %w", topic, w.group, err)
+	}
+	return nil
+}
+
+// loop 按间隔执行fn 直至ctx结束
+func (w worker) loop(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// consume 拉取并处理主题任务 直至ctx结束 workCtx为任务处理使用的ctx
+func (w worker) consume(ctx, workCtx context.Context, h handler) {
+	for ctx.Err() == nil {
+		streams, err := w.rdb.XReadGroup(workCtx, &redis.XReadGroupArgs{
+			Group:    w.group,
+			Consumer: w.consumer,
+			Streams:  []string{streamKey(h.topic), ">"},
+			Count:    1,
+			Block:    w.conf.BlockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if workCtx.Err() != nil {
+				return
+			}
+			w.logger.WithField("topic", h.topic).WithError(err).Error("拉取任务错误")
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = w.createGroup(workCtx, h.topic)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				w.process(workCtx, h, msg)
+			}
+		}
+	}
+}
+
+// process 处理一条任务消息 并按处理结果确认、重试或转入死信流
+func (w worker) process(ctx context.Context, h handler, msg redis.XMessage) {
+	m, err := parseMessage(msg)
+	if err != nil {
+		w.settle(h, msg.ID, m, time.Now(), fmt.Errorf("%w: %w", ErrSkipRetry, err))
+		return
+	}
+	m.Attempt++
+	task := Task{
+		ID:         m.ID,
+		Topic:      h.topic,
+		MessageID:  msg.ID,
+		Attempt:    m.Attempt,
+		MaxRetries: h.maxRetries,
+		EnqueuedAt: time.UnixMilli(m.EnqueuedAt),
+		Unique:     m.Unique,
+	}
+	// 沿用投递方请求ID与链路ID 便于串联投递与处理日志
+	meta := kit.NewMeta()
+	if m.RequestID != "" {
+		meta.RequestID = m.RequestID
+	}
+	if m.TraceID != "" {
+		meta.TraceID = m.TraceID
+	}
+	ctx = kit.WithMeta(ctx, meta)
+	start := time.Now()
+	stop := w.keepClaimed(h.topic, msg.ID)
+	err = w.run(ctx, h, task, m.Payload)
+	stop()
+	w.settle(h, msg.ID, m, start, err)
+}
+
+// keepClaimed 处理期间定期续期任务消息 重置其空闲时间 避免处理超过可见性超时的任务被重复认领
+// 返回停止续期的函数
+func (w worker) keepClaimed(topic, msgID string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.loop(ctx, w.conf.VisibilityTimeout/3, func() {
+			rctx, rcancel := context.WithTimeout(ctx, redisTimeout)
+			defer rcancel()
+			err := w.rdb.XClaimJustID(rctx, &redis.XClaimArgs{
+				Stream:   streamKey(topic),
+				Group:    w.group,
+				Consumer: w.consumer,
+				Messages: []string{msgID},
+			}).Err()
+			if err != nil && ctx.Err() == nil {
+				w.logger.WithFields(logrus.Fields{"topic": topic, "message_id": msgID}).WithError(err).Error("续期处理中的任务错误")
+			}
+		})
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// run 运行一次处理器 发生panic时恢复并发送报警
+func (w worker) run(ctx context.Context, h handler, task Task, payload string) (err error) {
+	var cancel context.CancelFunc
+	if h.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			err = fmt.Errorf("发生panic: %v", r)
+			w.logger.WithContext(ctx).WithFields(logrus.Fields{"topic": task.Topic, "task_id": task.ID, "stack": string(buf)}).
+				Errorf("任务主题[%s]处理发生panic", task.Topic)
+			feishu.Alert(fmt.Sprintf("[%s]Queue Panic!!!", config.AppName()),
+				fmt.Sprintf("请注意: 任务主题[%s]任务[%s]处理发生了Panic!!!\nPanic: %#v", task.Topic, task.ID, r))
+		}
+	}()
+	return h.run(context.WithValue(ctx, taskKey{}, task), []byte(payload))
+}
+
+// settle 按处理结果确认任务 失败时按退避策略重新投递到延迟集合或转入死信流 并记录处理日志
+func (w worker) settle(h handler, msgID string, m message, start time.Time, err error) {
+	ctx, cancel := context.WithTimeout(kit.WithMeta(context.Background(), kit.Meta{RequestID: m.RequestID, TraceID: m.TraceID}), redisTimeout)
+	defer cancel()
+
+	outcome := OutcomeSuccess
+	entry := w.logger.WithContext(ctx).WithFields(logrus.Fields{
+		"topic":      h.topic,
+		"task_id":    m.ID,
+		"message_id": msgID,
+		"unique":     m.Unique,
+		"attempt":    m.Attempt,
+		"duration":   time.Since(start).String(),
+	})
+	if err != nil {
+		outcome = OutcomeDead
+		if m.Attempt <= h.maxRetries && !errors.Is(err, ErrSkipRetry) {
+			outcome = OutcomeRetry
+		}
+	}
+
+	stream := streamKey(h.topic)
+	_, rerr := w.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		switch outcome {
+		case OutcomeRetry:
+			delay := kit.Backoff(w.conf.Backoff.Initial, cmp.Or(w.conf.Backoff.Max, 10*time.Minute), w.conf.Backoff.Multiplier, m.Attempt)
+			entry = entry.WithField("retry_in", delay.String())
+			if err := schedule(ctx, pipe, h.topic, m, time.Now().Add(delay)); err != nil {
+				return err
+			}
+		case OutcomeDead:
+			values := append(m.values(), "error", err.Error(), "failed_at", time.Now().Format(time.DateTime))
+			args := &redis.XAddArgs{Stream: deadKey(h.topic), Values: values}
+			if w.conf.DeadLetter.MaxLen > 0 {
+				args.MaxLen = w.conf.DeadLetter.MaxLen
+				args.Approx = true
+			}
+			pipe.XAdd(ctx, args)
+		}
+		pipe.XAck(ctx, stream, w.group, msgID)
+		pipe.XDel(ctx, stream, msgID)
+		if m.Unique != "" && outcome != OutcomeRetry {
+			releaseScript.Eval(ctx, pipe, []string{uniqueKey(h.topic, m.Unique)}, m.ID)
+		}
+		return nil
+	})
+
+	entry = entry.WithField("outcome", outcome)
+	if rerr != nil {
+		entry.WithError(rerr).Errorf("任务主题[%s]确认任务错误, 任务将在可见性超时后重新处理", h.topic)
+	}
+	switch outcome {
+	case OutcomeSuccess:
+		entry.Infof("任务主题[%s]任务处理成功", h.topic)
+	case OutcomeRetry:
+		entry.WithError(err).Warnf("任务主题[%s]任务处理失败, 等待重试", h.topic)
+	case OutcomeDead:
+		entry.WithError(err).Errorf("任务主题[%s]任务处理失败, 已转入死信流", h.topic)
+	}
+}
+
+// promote 将主题到期的延迟任务移入Stream
+func (w worker) promote(topic string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	for {
+		n, err := promoteScript.Run(ctx, w.rdb, []string{delayedKey(topic), streamKey(topic)},
+			time.Now().UnixMilli(), promoteBatch).Int()
+		if err != nil {
+			w.logger.WithField("topic", topic).WithError(err).Error("移入到期延迟任务错误")
+			return
+		}
+		if n < promoteBatch {
+			return
+		}
+	}
+}
+
+// reclaim 认领超过可见性超时仍未确认的任务 视为一次处理失败 按重试策略重新投递或转入死信流
+// 通常由消费者进程崩溃或处理超时未设置导致
+func (w worker) reclaim(h handler) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	start := "0-0"
+	for {
+		msgs, next, err := w.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   streamKey(h.topic),
+			Group:    w.group,
+			Consumer: w.consumer,
+			MinIdle:  w.conf.VisibilityTimeout,
+			Start:    start,
+			Count:    promoteBatch,
+		}).Result()
+		if err != nil {
+			w.logger.WithField("topic", h.topic).WithError(err).Error("认领超时未确认任务错误")
+			return
+		}
+		for _, msg := range msgs {
+			m, err := parseMessage(msg)
+			if err == nil {
+				m.Attempt++
+				err = fmt.Errorf("任务超过可见性超时[%s]未确认", w.conf.VisibilityTimeout)
+			} else {
+				err = fmt.Errorf("%w: %w", ErrSkipRetry, err)
+			}
+			w.settle(h, msg.ID, m, time.Now(), err)
+		}
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
@@ -1,7 +1,11 @@
 package session
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+
+	"github.com/zjutjh/mygo/config"
 )
 
 const (
@@ -37,3 +41,19 @@ type Config struct {
 	HttpOnly bool          `mapstructure:"http_only"`
 	SameSite http.SameSite `mapstructure:"same_site"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	switch c.Driver {
+	case DriverRedis, DriverMemory:
+	default:
+		return fmt.Errorf("driver[%s]非法, 可选值为%s/%s", c.Driver, DriverRedis, DriverMemory)
+	}
+	if c.Secret == "" {
+		return errors.New("secret不能为空")
+	}
+	if c.Secret == DefaultConfig.Secret && config.AppEnv() == config.AppEnvProd {
+		return errors.New("prod环境下secret不能使用默认值")
+	}
+	return nil
+}
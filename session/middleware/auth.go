@@ -12,7 +12,7 @@ import (
 // 参数: mustLogged 是否必须登录
 func Auth[T any](mustLogged bool) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		_, err := session.GetIdentity[T](ctx)
+		identity, err := session.GetIdentity[T](ctx)
 		if err != nil {
 			if !mustLogged {
 				ctx.Next()
@@ -21,5 +21,13 @@ func Auth[T any](mustLogged bool) gin.HandlerFunc {
 			reply.Fail(ctx, kit.CodeNotLoggedIn)
 			return
 		}
+
+		// 写入请求上下文 供日志记录
+		if s := kit.FormatIdentity(identity); s != "" {
+			ctx.Request = ctx.Request.WithContext(kit.WithIdentity(ctx.Request.Context(), s))
+			if meta, ok := kit.MetaFrom(ctx.Request.Context()); ok {
+				ctx.Set(kit.MetaKey, meta)
+			}
+		}
 	}
 }
@@ -33,10 +33,14 @@ func Pick(keys ...string) gin.HandlerFunc {
 	if !app.IsSet(key) {
 		panic(kit.ErrNotFound)
 	}
-	err = app.UnmarshalKey(key, &conf)
+	err = config.UnmarshalKeyStrict(app, key, &conf)
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("%w: 解析config.yaml[%s]错误: %w", kit.ErrDataUnmarshal, key, err))
+	}
+	if err = conf.Validate(); err != nil {
+		panic(fmt.Errorf("%w: 校验config.yaml[%s]错误: %w", kit.ErrConfigInvalid, key, err))
 	}
+	config.Track("session", key, conf)
 
 	var store sessions.Store
 	keyPairs := []byte(conf.Secret)
@@ -86,6 +86,22 @@ func gatherMiddlewares(engine *gin.Engine) (*middlewareMap, error) {
 	return middlewareMap, nil
 }
 
+// RouteMiddlewares 获取engine全部路由的中间件链 返回 method -> 完整路径 -> 中间件函数名列表
+func RouteMiddlewares(engine *gin.Engine) (map[string]map[string][]string, error) {
+	middlewareMap, err := gatherMiddlewares(engine)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]map[string][]string, len(middlewareMap.mapping))
+	for method, paths := range middlewareMap.mapping {
+		res[method] = make(map[string][]string, len(paths))
+		for path, names := range paths {
+			res[method][path] = names
+		}
+	}
+	return res, nil
+}
+
 // 递归解析树节点
 func parseNode(method string, node reflect.Value, parentPath string, middlewareMap *middlewareMap) error {
 	node = dereference(node)
@@ -1,6 +1,8 @@
 package miniProgram
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -89,3 +91,16 @@ type MemCacheConfig struct {
 	DefaultLifeTime time.Duration `mapstructure:"default_life_time"`
 	Prefix          string        `mapstructure:"prefix"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.AppID == "" {
+		return errors.New("app_id不能为空")
+	}
+	switch c.Cache.Driver {
+	case CacheDriverRedis, CacheDriverMemory:
+	default:
+		return fmt.Errorf("cache.driver[%s]非法, 可选值为%s/%s", c.Cache.Driver, CacheDriverRedis, CacheDriverMemory)
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package officialAccount
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -96,3 +98,16 @@ type OAuthConfig struct {
 	Scopes   []string `mapstructure:"scopes"`
 	Callback string   `mapstructure:"callback"`
 }
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.AppID == "" {
+		return errors.New("app_id不能为空")
+	}
+	switch c.Cache.Driver {
+	case CacheDriverRedis, CacheDriverMemory:
+	default:
+		return fmt.Errorf("cache.driver[%s]非法, 可选值为%s/%s", c.Cache.Driver, CacheDriverRedis, CacheDriverMemory)
+	}
+	return nil
+}